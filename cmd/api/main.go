@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os/signal"
+	"strings"
 	"syscall"
+	_ "time/tzdata"
 
 	"github.com/RamanDudoits/shortLink-go/internal/config"
 	"github.com/RamanDudoits/shortLink-go/internal/repository/postgres"
@@ -15,7 +19,6 @@ import (
 	"github.com/RamanDudoits/shortLink-go/pkg/validator"
 )
 
-
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -32,24 +35,142 @@ func main() {
 
 	userRepo := postgres.NewUserRepository(db.Poll)
 	linkRepo := postgres.NewLinkRepository(db.Poll)
+	tagRepo := postgres.NewTagRepository(db.Poll)
+	folderRepo := postgres.NewFolderRepository(db.Poll)
+	linkHealthRepo := postgres.NewLinkHealthRepository(db.Poll)
+	clickRepo := postgres.NewClickRepository(db.Poll)
+	domainRepo := postgres.NewDomainRepository(db.Poll)
+	domainRuleRepo := postgres.NewDomainRuleRepository(db.Poll)
+	visitorRepo := postgres.NewVisitorRepository(db.Poll)
+
+	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.Expire)
+	var titleResolver *service.TitleResolver
+	if cfg.Titles.Enabled {
+		titleFetcher := service.NewHTTPTitleFetcher(cfg.Titles.Timeout)
+		titleResolver = service.NewTitleResolver(linkRepo, titleFetcher, cfg.Titles.Workers, cfg.Titles.Timeout)
+	}
+
+	urlPolicy := service.NewURLPolicy(cfg.URLs.AllowedSchemes, cfg.URLs.MaxLength, cfg.URLs.SortQuery)
+	domainFilter := service.NewDomainFilter(cfg.Domains.RulesFile, domainRuleRepo)
+	if err := domainFilter.Reload(); err != nil {
+		log.Fatalf("failed to load domain rules: %v", err)
+	}
+
+	geoIP := service.NewGeoIPDatabase(cfg.GeoIP.Database, cfg.GeoIP.ReloadInterval)
+	if err := geoIP.Reload(); err != nil {
+		log.Fatalf("failed to load geoip database: %v", err)
+	}
 
-	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.JWT.Expire,)
-	linkService := service.NewLinkService(linkRepo)
+	botFilter, err := service.NewBotFilter(cfg.Bots.PatternsFile)
+	if err != nil {
+		log.Fatalf("failed to load bot patterns: %v", err)
+	}
+
+	var threatScanner service.ThreatScanner
+	switch cfg.Threats.Scanner {
+	case "":
+	case "safebrowsing":
+		threatScanner = service.NewSafeBrowsingScanner(cfg.Threats.Endpoint, cfg.Threats.APIKey, cfg.Threats.Timeout)
+	default:
+		log.Fatalf("unknown threat scanner %q", cfg.Threats.Scanner)
+	}
+
+	linkService := service.NewLinkService(
+		linkRepo, tagRepo, folderRepo, clickRepo, domainRepo,
+		urlPolicy, domainFilter, threatScanner, titleResolver, geoIP, botFilter,
+		service.NewVisitorCounter(visitorRepo),
+	)
+	tagService := service.NewTagService(tagRepo)
+	folderService := service.NewFolderService(folderRepo)
+	qrService := service.NewQRService(cfg.QR.CacheSize)
+
+	var txtResolver service.TXTResolver
+	switch cfg.CustomDomains.Resolver {
+	case "":
+		txtResolver = net.DefaultResolver
+	case "static":
+		records := map[string][]string{}
+		for _, record := range cfg.CustomDomains.StaticTXT {
+			name, value, _ := strings.Cut(record, "=")
+			records[name] = append(records[name], value)
+		}
+		txtResolver = service.NewStaticTXTResolver(records)
+	default:
+		log.Fatalf("unknown dns resolver %q", cfg.CustomDomains.Resolver)
+	}
+	var ownHosts []string
+	if baseURL, err := url.Parse(cfg.HTTP.BaseURL); err == nil && baseURL.Hostname() != "" {
+		ownHosts = append(ownHosts, baseURL.Hostname())
+	}
+	domainService := service.NewDomainService(
+		domainRepo, txtResolver, cfg.CustomDomains.VerifyTimeout, ownHosts,
+		urlPolicy, domainFilter, threatScanner,
+	)
+	// The global fallback passes the same checks as the domains' own.
+	fallbackURL, err := domainService.CheckRedirectURL(cfg.ErrorPages.FallbackURL)
+	if err != nil {
+		log.Fatalf("invalid ERROR_FALLBACK_URL: %v", err)
+	}
+	trashPurger := service.NewTrashPurger(linkRepo, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	domainRescanner := service.NewDomainRescanner(linkRepo, domainFilter, cfg.Domains.RescanInterval)
+	domainRuleService := service.NewDomainRuleService(domainRuleRepo, domainFilter, domainRescanner)
 
 	validator := validator.New()
+	clientIPs, err := handler.NewClientIPResolver(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		log.Fatalf("failed to parse trusted proxies: %v", err)
+	}
 
 	authHandler := handler.NewAuthHandler(authService, validator)
-	linkHandler := handler.NewLinkHandler(linkService, linkService)
+	errorPages := handler.NewErrorPages(domainService, fallbackURL)
+	proceedTokens := service.NewProceedTokens(cfg.JWT.Secret, cfg.Threats.ProceedTTL)
+	linkHandler := handler.NewLinkHandler(linkService, linkService, validator, clientIPs, errorPages, proceedTokens)
+	tagHandler := handler.NewTagHandler(tagService)
+	folderHandler := handler.NewFolderHandler(folderService)
+	qrHandler := handler.NewQRHandler(linkService, linkService, qrService, cfg.HTTP.BaseURL, errorPages)
+	domainHandler := handler.NewDomainHandler(domainService)
+	adminHandler := handler.NewAdminHandler(domainRuleService, cfg.Admin.UserIDs)
+	appLinksHandler := handler.NewAppLinksHandler(
+		cfg.AppLinks.AppleAppIDs, cfg.AppLinks.AndroidPackage, cfg.AppLinks.AndroidFingerprints,
+	)
+
+	r := router.NewRouter(authHandler, linkHandler, tagHandler, folderHandler, qrHandler, appLinksHandler, domainHandler, adminHandler)
 
-	r := router.NewRouter(authHandler, linkHandler)
-	
 	server := &http.Server{
-		Addr: cfg.HTTP.Addr,
-		Handler: r,
-		ReadTimeout: cfg.HTTP.ReadTimeout,
+		Addr:         cfg.HTTP.Addr,
+		Handler:      r,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
 		WriteTimeout: cfg.HTTP.WriteTimeout,
 	}
 
+	go trashPurger.Run(ctx)
+	// Admins can add rules at any time, so the rescanner always runs.
+	go domainRescanner.Run(ctx)
+	if cfg.GeoIP.Database != "" {
+		go geoIP.Run(ctx)
+	}
+	if titleResolver != nil {
+		go titleResolver.Run(ctx)
+	}
+	if cfg.Health.Enabled {
+		var notifier service.BrokenLinkNotifier
+		if cfg.Health.NotifyWebhook != "" {
+			notifier = service.NewWebhookNotifier(cfg.Health.NotifyWebhook, cfg.Health.Timeout)
+		}
+		healthChecker := service.NewHealthChecker(
+			linkRepo, linkHealthRepo, notifier,
+			cfg.Health.Interval, cfg.Health.Timeout, cfg.Health.HostDelay,
+			cfg.Health.Workers, cfg.Health.FailureThreshold,
+		)
+		go healthChecker.Run(ctx)
+	}
+	if threatScanner != nil {
+		threatRescanner := service.NewThreatRescanner(
+			linkRepo, threatScanner, cfg.Threats.RescanInterval, cfg.Threats.Timeout,
+		)
+		go threatRescanner.Run(ctx)
+	}
+
 	go func() {
 		log.Printf("Server started on %s", cfg.HTTP.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -57,7 +178,6 @@ func main() {
 		}
 	}()
 
-
 	<-ctx.Done()
 	log.Println("shutting down server...")
-}
\ No newline at end of file
+}
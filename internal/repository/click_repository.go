@@ -0,0 +1,51 @@
+package repository
+
+import "time"
+
+type ClickRepository interface {
+	Record(click *Click) error
+	CountByGeo(query ClickStatsQuery) (*GeoStats, error)
+	CountByVariant(query ClickStatsQuery) ([]*VariantCount, error)
+}
+
+// Click is a single counted visit of a short link.
+type Click struct {
+	ID        int64     `json:"id"`
+	LinkID    int       `json:"link_id" db:"short_link_id"`
+	Source    string    `json:"source" db:"source"`
+	Country   string    `json:"country" db:"country"`
+	Region    string    `json:"region" db:"region"`
+	Variant   string    `json:"variant,omitempty" db:"variant"`
+	Bot       bool      `json:"bot" db:"bot"`
+	ClickedAt time.Time `json:"clicked_at" db:"clicked_at"`
+}
+
+// ClickStatsQuery selects the clicks of a link, optionally limited to a time
+// range. From is inclusive and To is exclusive. Clicks by bots are left out
+// unless IncludeBots is set.
+type ClickStatsQuery struct {
+	LinkID      int
+	From        *time.Time
+	To          *time.Time
+	IncludeBots bool
+}
+
+// GeoStats splits clicks by the visitor's country and region. Clicks whose
+// location could not be resolved are counted under UnknownLocation.
+type GeoStats struct {
+	Countries []*GeoCount `json:"countries"`
+	Regions   []*GeoCount `json:"regions"`
+}
+
+type GeoCount struct {
+	Code   string `json:"code"`
+	Clicks int    `json:"clicks"`
+}
+
+const UnknownLocation = "unknown"
+
+// VariantCount is how many clicks were sent to an A/B variant.
+type VariantCount struct {
+	Variant string `json:"variant"`
+	Clicks  int    `json:"clicks"`
+}
@@ -0,0 +1,73 @@
+package repository
+
+import "time"
+
+type DomainRepository interface {
+	Create(domain *Domain) (*Domain, error)
+	FindByID(id int) (*Domain, error)
+	FindByUserID(userID int) ([]*Domain, error)
+	FindVerifiedByHost(host string) (*Domain, error)
+	MarkVerified(id int) (*Domain, error)
+	SetErrorPages(id int, pages *DomainErrorPages) error
+	HasLinks(id int) (bool, error)
+	Delete(id, userID int) error
+}
+
+// Domain is a custom host a user serves short links from. Links can only be
+// created on it once ownership is proven with a DNS TXT record containing
+// VerificationToken.
+type Domain struct {
+	ID                int        `json:"id"`
+	UserID            int        `json:"user_id" db:"user_id"`
+	Host              string     `json:"host" db:"host"`
+	VerificationToken string     `json:"-" db:"verification_token"`
+	VerifiedAt        *time.Time `json:"verified_at" db:"verified_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	// ErrorPages replaces the service's error pages for links on the domain.
+	ErrorPages *DomainErrorPages `json:"error_pages,omitempty" db:"error_pages"`
+}
+
+// Kinds of error pages shown instead of a redirect.
+const (
+	ErrorPageNotFound = "not_found"
+	ErrorPageExpired  = "expired"
+	ErrorPageDisabled = "disabled"
+	ErrorPageBlocked  = "blocked"
+)
+
+// DomainErrorPages holds a domain's own error pages. A kind without its own
+// page redirects to FallbackURL when it is set, and shows the default page
+// otherwise.
+type DomainErrorPages struct {
+	FallbackURL string     `json:"fallback_url,omitempty"`
+	NotFound    *ErrorPage `json:"not_found,omitempty"`
+	Expired     *ErrorPage `json:"expired,omitempty"`
+	Disabled    *ErrorPage `json:"disabled,omitempty"`
+	Blocked     *ErrorPage `json:"blocked,omitempty"`
+}
+
+// Page returns the page for the kind, or nil.
+func (p *DomainErrorPages) Page(kind string) *ErrorPage {
+	if p == nil {
+		return nil
+	}
+	switch kind {
+	case ErrorPageNotFound:
+		return p.NotFound
+	case ErrorPageExpired:
+		return p.Expired
+	case ErrorPageDisabled:
+		return p.Disabled
+	case ErrorPageBlocked:
+		return p.Blocked
+	}
+	return nil
+}
+
+// ErrorPage either redirects to RedirectURL or shows the default page with
+// Title and Message in place of the default texts.
+type ErrorPage struct {
+	Title       string `json:"title,omitempty"`
+	Message     string `json:"message,omitempty"`
+	RedirectURL string `json:"redirect_url,omitempty"`
+}
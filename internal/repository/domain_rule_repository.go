@@ -0,0 +1,24 @@
+package repository
+
+import "time"
+
+type DomainRuleRepository interface {
+	Create(rule *DomainRule) (*DomainRule, error)
+	FindAll() ([]*DomainRule, error)
+	Delete(id int) error
+}
+
+// Domain rule actions.
+const (
+	DomainRuleBlock = "block"
+	DomainRuleAllow = "allow"
+)
+
+// DomainRule blocks or allows a destination domain. Pattern is a domain,
+// or "*." followed by a domain for any of its subdomains.
+type DomainRule struct {
+	ID        int       `json:"id"`
+	Action    string    `json:"action" db:"action"`
+	Pattern   string    `json:"pattern" db:"pattern"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package repository
+
+import "errors"
+
+// Errors returned by the repositories when a record is missing or a unique
+// value is already in use. Services return them as they are.
+var (
+	ErrLinkNotFound       = errors.New("link not found")
+	ErrVersionNotFound    = errors.New("version not found")
+	ErrFolderNotFound     = errors.New("folder not found")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagExists          = errors.New("tag already exists")
+	ErrDomainNotFound     = errors.New("domain not found")
+	ErrDomainExists       = errors.New("domain already registered")
+	ErrDomainRuleNotFound = errors.New("domain rule not found")
+	ErrDomainRuleExists   = errors.New("domain rule already exists")
+	ErrShortCodeTaken     = errors.New("short code already taken")
+)
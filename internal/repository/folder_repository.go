@@ -0,0 +1,26 @@
+package repository
+
+import "time"
+
+type FolderRepository interface {
+	Create(folder *Folder) (*Folder, error)
+	FindByID(id int) (*Folder, error)
+	FindByUserID(userID int) ([]*Folder, error)
+	Update(folder *Folder) (*Folder, error)
+	Delete(id, userID int) error
+	IsDescendant(folderID, ancestorID int) (bool, error)
+	MoveLink(linkID int, folderID *int) error
+}
+
+// Folder groups links in a tree. LinkCount and ClickCount cover the folder's
+// own links; TotalClickCount also includes every subfolder.
+type Folder struct {
+	ID              int       `json:"id"`
+	UserID          int       `json:"user_id" db:"user_id"`
+	ParentID        *int      `json:"parent_id" db:"parent_id"`
+	Name            string    `json:"name" db:"name"`
+	LinkCount       int       `json:"link_count"`
+	ClickCount      int       `json:"click_count"`
+	TotalClickCount int       `json:"total_click_count"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "time"
+
+type LinkHealthRepository interface {
+	Save(health *LinkHealth) (*LinkHealth, error)
+	MarkNotified(linkID int) error
+}
+
+// LinkHealth is the result of the latest destination check. Links with
+// several destinations keep the result of the first failing one.
+type LinkHealth struct {
+	LinkID              int        `json:"-" db:"short_link_id"`
+	URL                 string     `json:"url" db:"url"`
+	Healthy             bool       `json:"healthy" db:"healthy"`
+	StatusCode          int        `json:"status_code,omitempty" db:"status_code"`
+	LatencyMS           int        `json:"latency_ms" db:"latency_ms"`
+	RedirectChain       []string   `json:"redirect_chain" db:"redirect_chain"`
+	Error               string     `json:"error,omitempty" db:"error"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	NotifiedAt          *time.Time `json:"-" db:"notified_at"`
+	CheckedAt           time.Time  `json:"checked_at" db:"checked_at"`
+}
@@ -3,20 +3,287 @@ package repository
 import "time"
 
 type LinkRepository interface {
-    Create(link *Link) (*Link, error)
-    FindByID(id int) (*Link, error)
-    FindByUserID(userID int) ([]*Link, error)
-    FindByURLAndUser(url string, userID int) (*Link, error)
-    Update(id int, updates map[string]interface{}) (*Link, error)
-    Delete(id, userID int) error
-    Find(filter map[string]interface{}) (*Link, error)
+	Create(link *Link) (*Link, error)
+	FindByID(id int) (*Link, error)
+	FindByUserID(userID int) ([]*Link, error)
+	EachByUserID(userID int, fn func(*Link) error) error
+	FindPage(query LinkListQuery) (*LinkPage, error)
+	Search(userID int, query string, limit int) ([]*LinkSearchResult, error)
+	FindByURLAndUser(url string, userID int, domainID *int) (*Link, error)
+	FindByShortCode(domainID *int, shortCode string) (*Link, error)
+	Update(id int, update LinkUpdate) (*Link, error)
+	Delete(id, userID int) error
+	Find(filter map[string]interface{}) (*Link, error)
+	FindVersions(linkID int) ([]*LinkVersion, error)
+	FindVersion(linkID, version int) (*LinkVersion, error)
+	FindDeletedByUserID(userID int) ([]*Link, error)
+	Restore(id, userID int) error
+	PurgeDeleted(before time.Time) (int64, error)
+	ShortCodeExists(domainID *int, shortCode string) (bool, error)
+	SetTitleIfEmpty(id int, title string) error
+	EachLink(fn func(*Link) error) error
+	FindActiveAfter(afterID, limit int) ([]*Link, error)
+	SetDisabled(id int, reason string) error
+	SetThreat(id int, threatType string) error
+	SetDestinationThreats(id int, threats map[string]string) error
+	SetTargetingRules(id int, rules []TargetingRule) error
+	SetVariants(id int, variants []LinkVariant) error
+	SetSchedule(id int, schedule *LinkSchedule) error
+	SetDeepLink(id int, deepLink *DeepLink) error
+}
+
+// LinkUpdate holds the fields changed by a partial update. Nil fields are
+// left as they are.
+type LinkUpdate struct {
+	OriginalURL *string
+	ShortCode   *string
+	Title       *string
+	Description *string
+	Notes       *string
+
+	Interstitial *bool
+
+	UTMSource        *string
+	UTMMedium        *string
+	UTMCampaign      *string
+	UTMTerm          *string
+	UTMContent       *string
+	QueryPassthrough *bool
+
+	// Routing replaces the targeting rules, variants, schedule and deep link
+	// together, as when reverting to a version.
+	Routing *LinkRouting
+}
+
+// LinkRouting holds the settings that send visitors to destinations other
+// than the link's own URL.
+type LinkRouting struct {
+	TargetingRules []TargetingRule `json:"targeting_rules"`
+	Variants       []LinkVariant   `json:"variants"`
+	Schedule       *LinkSchedule   `json:"schedule"`
+	DeepLink       *DeepLink       `json:"deep_link"`
+}
+
+// LinkSettings are the settings a version keeps besides the link's own
+// fields.
+type LinkSettings struct {
+	Interstitial     bool   `json:"interstitial"`
+	UTMSource        string `json:"utm_source"`
+	UTMMedium        string `json:"utm_medium"`
+	UTMCampaign      string `json:"utm_campaign"`
+	UTMTerm          string `json:"utm_term"`
+	UTMContent       string `json:"utm_content"`
+	QueryPassthrough bool   `json:"query_passthrough"`
+	LinkRouting
 }
 
 type Link struct {
-    ID          int       `json:"id"`
-    OriginalURL string    `json:"original_url" db:"link"`
-    ShortCode   string    `json:"short_code" db:"short_link"`
-    UserID      int       `json:"user_id" db:"user_id"`
-    ClickCount  int       `json:"click_count" db:"clicks"`
-    CreatedAt   time.Time `json:"created_at" db:"created_at"`
-}
\ No newline at end of file
+	ID          int        `json:"id"`
+	OriginalURL string     `json:"original_url" db:"link"`
+	ShortCode   string     `json:"short_code" db:"short_link"`
+	Title       string     `json:"title" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Notes       string     `json:"notes" db:"notes"`
+	Tags        []string   `json:"tags"`
+	FolderID    *int       `json:"folder_id" db:"folder_id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	ClickCount  int        `json:"click_count" db:"clicks"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// DomainID is the custom domain the link is served from, nil for the
+	// service's own host. Domain is that domain's host.
+	DomainID *int   `json:"domain_id" db:"domain_id"`
+	Domain   string `json:"domain,omitempty"`
+
+	// ClickSources splits ClickCount by where the visit came from.
+	ClickSources map[string]int `json:"click_sources"`
+	// BotClickCount counts visits by bots and link unfurlers, which are left
+	// out of ClickCount.
+	BotClickCount int `json:"bot_click_count" db:"bot_clicks"`
+
+	// Interstitial makes the link always show the preview page instead of
+	// redirecting straight away.
+	Interstitial bool `json:"interstitial" db:"interstitial"`
+
+	// UTM parameters added to the destination on redirect.
+	UTMSource   string `json:"utm_source" db:"utm_source"`
+	UTMMedium   string `json:"utm_medium" db:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign" db:"utm_campaign"`
+	UTMTerm     string `json:"utm_term" db:"utm_term"`
+	UTMContent  string `json:"utm_content" db:"utm_content"`
+
+	// QueryPassthrough forwards the query string of the short URL to the
+	// destination.
+	QueryPassthrough bool `json:"query_passthrough" db:"query_passthrough"`
+
+	// TargetingRules send matching visitors to other destinations. They are
+	// checked in order and OriginalURL is the fallback.
+	TargetingRules []TargetingRule `json:"targeting_rules" db:"targeting_rules"`
+
+	// Variants split the remaining visitors between several destinations by
+	// weight. Targeting rules take precedence over them.
+	Variants []LinkVariant `json:"variants" db:"variants"`
+
+	// Schedule limits when the link is active and changes its destination
+	// over time. Nil means always active.
+	Schedule *LinkSchedule `json:"schedule" db:"schedule"`
+
+	// DeepLink opens the visitor's mobile app instead of the website.
+	DeepLink *DeepLink `json:"deep_link" db:"deep_link"`
+
+	DisabledReason string     `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+
+	// ThreatType is set when the threat scanner flagged the destination.
+	// Such links redirect through a warning page.
+	ThreatType string `json:"threat_type,omitempty" db:"threat_type"`
+	// DestinationThreats holds the threat types of the link's other
+	// destinations, such as targeting rule URLs, keyed by URL. Clean
+	// destinations are left out.
+	DestinationThreats map[string]string `json:"destination_threats,omitempty" db:"destination_threats"`
+
+	// Health is the latest destination check, nil until the link is checked.
+	Health *LinkHealth `json:"health,omitempty"`
+}
+
+// Settings returns the link's settings as kept in versions.
+func (l *Link) Settings() LinkSettings {
+	return LinkSettings{
+		Interstitial:     l.Interstitial,
+		UTMSource:        l.UTMSource,
+		UTMMedium:        l.UTMMedium,
+		UTMCampaign:      l.UTMCampaign,
+		UTMTerm:          l.UTMTerm,
+		UTMContent:       l.UTMContent,
+		QueryPassthrough: l.QueryPassthrough,
+		LinkRouting: LinkRouting{
+			TargetingRules: l.TargetingRules,
+			Variants:       l.Variants,
+			Schedule:       l.Schedule,
+			DeepLink:       l.DeepLink,
+		},
+	}
+}
+
+// TargetingRule matches visitors by their device and location. Every
+// non-empty condition must contain the visitor's value for the rule to apply.
+// Countries are ISO 3166-1 alpha-2 codes and regions ISO 3166-2 codes.
+type TargetingRule struct {
+	OS      []string `json:"os,omitempty"`
+	Device  []string `json:"device,omitempty"`
+	Browser []string `json:"browser,omitempty"`
+	Country []string `json:"country,omitempty"`
+	Region  []string `json:"region,omitempty"`
+	URL     string   `json:"url"`
+}
+
+// LinkVariant is one destination of an A/B split. A visitor gets a variant
+// with probability Weight divided by the sum of all weights.
+type LinkVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// LinkSchedule holds wall-clock times in the "2006-01-02T15:04" format, read
+// in Timezone, an IANA zone name. Before ActiveFrom visitors go to PendingURL,
+// or get an error if it is empty; from ActiveUntil on the link has expired.
+// Changes replace the link's own URL from their time on.
+type LinkSchedule struct {
+	Timezone    string                 `json:"timezone"`
+	ActiveFrom  string                 `json:"active_from,omitempty"`
+	ActiveUntil string                 `json:"active_until,omitempty"`
+	PendingURL  string                 `json:"pending_url,omitempty"`
+	Changes     []ScheduledDestination `json:"changes,omitempty"`
+}
+
+type ScheduledDestination struct {
+	At  string `json:"at"`
+	URL string `json:"url"`
+}
+
+// DeepLink holds the app targets of a link. IOSURL and AndroidURL are
+// universal links and app links, regular https URLs the app claims, used for
+// visitors on that platform. AppURL uses the app's own scheme, such as
+// myapp://item/42; mobile visitors get a page that tries to open it and
+// falls back to the web destination when the app is not installed.
+type DeepLink struct {
+	IOSURL     string `json:"ios_url,omitempty"`
+	AndroidURL string `json:"android_url,omitempty"`
+	AppURL     string `json:"app_url,omitempty"`
+}
+
+// Click sources. Visits through a QR code are counted separately from
+// everything else.
+const (
+	ClickSourceDirect = "direct"
+	ClickSourceQR     = "qr"
+)
+
+// Reasons a link can be disabled for. A disabled link stays visible to its
+// owner but no longer redirects.
+const (
+	LinkDisabledBlockedDomain = "blocked_domain"
+)
+
+// LinkVersion is a snapshot of a link's destination and settings taken
+// every time one of them changes.
+type LinkVersion struct {
+	ID          int       `json:"id"`
+	LinkID      int       `json:"link_id" db:"short_link_id"`
+	Version     int       `json:"version" db:"version"`
+	OriginalURL string    `json:"original_url" db:"link"`
+	ShortCode   string    `json:"short_code" db:"short_link"`
+	Title       string    `json:"title" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// Settings is nil for versions recorded before settings were versioned.
+	Settings *LinkSettings `json:"settings" db:"settings"`
+}
+
+const (
+	LinkSortCreatedAt = "created_at"
+	LinkSortClicks    = "clicks"
+	LinkSortName      = "name"
+)
+
+// LinkListQuery describes one page of a user's links. Pages are addressed
+// by keyset cursors rather than offsets, so rows inserted between requests
+// do not shift or duplicate results.
+type LinkListQuery struct {
+	UserID          int
+	Sort            string
+	Desc            bool
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	DestinationHost string
+	DomainID        *int
+	Tag             string
+	FolderID        *int
+	After           *LinkCursor
+	Limit           int
+}
+
+// LinkCursor holds the sort key and id of the last link on a page. Only the
+// field matching the query's sort is used.
+type LinkCursor struct {
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Clicks    int       `json:"clicks,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	ID        int       `json:"id"`
+}
+
+type LinkPage struct {
+	Links []*Link
+	Total int
+	Next  *LinkCursor
+}
+
+// LinkSearchResult is a link matched by full-text or trigram search. Rank is
+// higher for better matches.
+type LinkSearchResult struct {
+	Link *Link
+	Rank float64
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ClickRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewClickRepository(db *pgxpool.Pool) *ClickRepository {
+	return &ClickRepository{db: db}
+}
+
+// Record stores the click and bumps the link's counters in a single
+// statement, so concurrent redirects of the same link do not overwrite each
+// other. Clicks by bots only bump bot_clicks.
+func (r *ClickRepository) Record(click *repository.Click) error {
+	err := r.db.QueryRow(context.Background(),
+		`WITH link AS (
+			UPDATE short_links
+			SET clicks = clicks + CASE WHEN $6 THEN 0 ELSE 1 END,
+				bot_clicks = bot_clicks + CASE WHEN $6 THEN 1 ELSE 0 END
+			WHERE id = $1
+		), source AS (
+			INSERT INTO link_click_sources (short_link_id, source, clicks)
+			SELECT $1, $2, 1 WHERE NOT $6::boolean
+			ON CONFLICT (short_link_id, source) DO UPDATE SET clicks = link_click_sources.clicks + 1
+		)
+		INSERT INTO link_clicks (short_link_id, source, country, region, variant, bot)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, clicked_at`,
+		click.LinkID, click.Source, click.Country, click.Region, click.Variant, click.Bot,
+	).Scan(&click.ID, &click.ClickedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record click: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ClickRepository) CountByGeo(query repository.ClickStatsQuery) (*repository.GeoStats, error) {
+	stats := &repository.GeoStats{}
+
+	countries, err := r.countBy("country", query)
+	if err != nil {
+		return nil, err
+	}
+	stats.Countries = countries
+
+	regions, err := r.countBy("region", query)
+	if err != nil {
+		return nil, err
+	}
+	stats.Regions = regions
+
+	return stats, nil
+}
+
+// countBy groups the link's clicks by a fixed location column.
+func (r *ClickRepository) countBy(column string, query repository.ClickStatsQuery) ([]*repository.GeoCount, error) {
+	rows, err := r.db.Query(context.Background(),
+		`SELECT COALESCE(NULLIF(`+column+`, ''), $4), COUNT(*)
+		FROM link_clicks
+		WHERE short_link_id = $1
+			AND ($2::timestamp IS NULL OR clicked_at >= $2)
+			AND ($3::timestamp IS NULL OR clicked_at < $3)
+			AND (NOT bot OR $5)
+		GROUP BY 1
+		ORDER BY 2 DESC, 1`,
+		query.LinkID, query.From, query.To, repository.UnknownLocation, query.IncludeBots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	counts := []*repository.GeoCount{}
+	for rows.Next() {
+		count := &repository.GeoCount{}
+		if err := rows.Scan(&count.Code, &count.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan clicks by %s: %w", column, err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// CountByVariant counts the link's clicks per A/B variant. Clicks made while
+// the link had no variants are left out.
+func (r *ClickRepository) CountByVariant(query repository.ClickStatsQuery) ([]*repository.VariantCount, error) {
+	rows, err := r.db.Query(context.Background(),
+		`SELECT variant, COUNT(*)
+		FROM link_clicks
+		WHERE short_link_id = $1 AND variant <> ''
+			AND ($2::timestamp IS NULL OR clicked_at >= $2)
+			AND ($3::timestamp IS NULL OR clicked_at < $3)
+			AND (NOT bot OR $4)
+		GROUP BY variant
+		ORDER BY variant`,
+		query.LinkID, query.From, query.To, query.IncludeBots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by variant: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []*repository.VariantCount{}
+	for rows.Next() {
+		count := &repository.VariantCount{}
+		if err := rows.Scan(&count.Variant, &count.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan clicks by variant: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
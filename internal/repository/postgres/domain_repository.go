@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const domainColumns = "id, user_id, host, verification_token, verified_at, created_at, error_pages"
+
+type DomainRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDomainRepository(db *pgxpool.Pool) *DomainRepository {
+	return &DomainRepository{db: db}
+}
+
+func scanDomain(row pgx.Row) (*repository.Domain, error) {
+	var domain repository.Domain
+	err := row.Scan(&domain.ID, &domain.UserID, &domain.Host, &domain.VerificationToken,
+		&domain.VerifiedAt, &domain.CreatedAt, &domain.ErrorPages)
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func (r *DomainRepository) Create(domain *repository.Domain) (*repository.Domain, error) {
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO domains (user_id, host, verification_token) VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		domain.UserID, domain.Host, domain.VerificationToken,
+	).Scan(&domain.ID, &domain.CreatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrDomainExists
+		}
+		return nil, fmt.Errorf("failed to create domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+func (r *DomainRepository) FindByID(id int) (*repository.Domain, error) {
+	domain, err := scanDomain(r.db.QueryRow(context.Background(),
+		"SELECT "+domainColumns+" FROM domains WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+func (r *DomainRepository) FindByUserID(userID int) ([]*repository.Domain, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT "+domainColumns+" FROM domains WHERE user_id = $1 ORDER BY host", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []*repository.Domain
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		domains = append(domains, domain)
+	}
+
+	return domains, rows.Err()
+}
+
+// FindVerifiedByHost returns the verified domain with the host, or nil when
+// there is none.
+func (r *DomainRepository) FindVerifiedByHost(host string) (*repository.Domain, error) {
+	domain, err := scanDomain(r.db.QueryRow(context.Background(),
+		"SELECT "+domainColumns+" FROM domains WHERE host = $1 AND verified_at IS NOT NULL", host))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+// MarkVerified verifies the domain. It fails with ErrDomainExists when
+// another registration of the host was verified first.
+func (r *DomainRepository) MarkVerified(id int) (*repository.Domain, error) {
+	domain, err := scanDomain(r.db.QueryRow(context.Background(),
+		`UPDATE domains SET verified_at = COALESCE(verified_at, NOW()) WHERE id = $1
+		RETURNING `+domainColumns, id))
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrDomainExists
+		}
+		return nil, fmt.Errorf("failed to mark domain as verified: %w", err)
+	}
+
+	return domain, nil
+}
+
+func (r *DomainRepository) SetErrorPages(id int, pages *repository.DomainErrorPages) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE domains SET error_pages = $2 WHERE id = $1", id, pages)
+	if err != nil {
+		return fmt.Errorf("failed to set error pages: %w", err)
+	}
+
+	return nil
+}
+
+// HasLinks reports whether any link, including those in the trash, uses the
+// domain.
+func (r *DomainRepository) HasLinks(id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(context.Background(),
+		"SELECT EXISTS(SELECT 1 FROM short_links WHERE domain_id = $1)", id,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check domain links: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *DomainRepository) Delete(id, userID int) error {
+	tag, err := r.db.Exec(context.Background(),
+		"DELETE FROM domains WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrDomainNotFound
+	}
+
+	return nil
+}
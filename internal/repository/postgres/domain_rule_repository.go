@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DomainRuleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDomainRuleRepository(db *pgxpool.Pool) *DomainRuleRepository {
+	return &DomainRuleRepository{db: db}
+}
+
+func (r *DomainRuleRepository) Create(rule *repository.DomainRule) (*repository.DomainRule, error) {
+	err := r.db.QueryRow(context.Background(),
+		"INSERT INTO domain_rules (action, pattern) VALUES ($1, $2) RETURNING id, created_at",
+		rule.Action, rule.Pattern,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrDomainRuleExists
+		}
+		return nil, fmt.Errorf("failed to create domain rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (r *DomainRuleRepository) FindAll() ([]*repository.DomainRule, error) {
+	rows, err := r.db.Query(context.Background(),
+		"SELECT id, action, pattern, created_at FROM domain_rules ORDER BY action, pattern")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*repository.DomainRule
+	for rows.Next() {
+		var rule repository.DomainRule
+		if err := rows.Scan(&rule.ID, &rule.Action, &rule.Pattern, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan domain rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *DomainRuleRepository) Delete(id int) error {
+	tag, err := r.db.Exec(context.Background(), "DELETE FROM domain_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete domain rule: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrDomainRuleNotFound
+	}
+
+	return nil
+}
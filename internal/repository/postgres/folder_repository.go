@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FolderRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFolderRepository(db *pgxpool.Pool) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+func (r *FolderRepository) Create(folder *repository.Folder) (*repository.Folder, error) {
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO folders (user_id, parent_id, name) VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		folder.UserID, folder.ParentID, folder.Name,
+	).Scan(&folder.ID, &folder.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+func (r *FolderRepository) FindByID(id int) (*repository.Folder, error) {
+	var folder repository.Folder
+	err := r.db.QueryRow(context.Background(),
+		`SELECT id, user_id, parent_id, name, created_at FROM folders WHERE id = $1`, id,
+	).Scan(&folder.ID, &folder.UserID, &folder.ParentID, &folder.Name, &folder.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	return &folder, nil
+}
+
+// FindByUserID returns all of the user's folders with link and click counts.
+// TotalClickCount is rolled up from subfolders after loading the tree.
+func (r *FolderRepository) FindByUserID(userID int) ([]*repository.Folder, error) {
+	query := `
+		SELECT f.id, f.user_id, f.parent_id, f.name, f.created_at,
+			COUNT(sl.id), COALESCE(SUM(sl.clicks), 0)
+		FROM folders f
+		LEFT JOIN short_links sl ON sl.folder_id = f.id AND sl.deleted_at IS NULL
+		WHERE f.user_id = $1
+		GROUP BY f.id
+		ORDER BY f.parent_id NULLS FIRST, lower(f.name)
+	`
+
+	rows, err := r.db.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query folders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []*repository.Folder
+	for rows.Next() {
+		var folder repository.Folder
+		if err := rows.Scan(
+			&folder.ID,
+			&folder.UserID,
+			&folder.ParentID,
+			&folder.Name,
+			&folder.CreatedAt,
+			&folder.LinkCount,
+			&folder.ClickCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan folder: %w", err)
+		}
+		folders = append(folders, &folder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	children := make(map[int][]*repository.Folder)
+	for _, folder := range folders {
+		if folder.ParentID != nil {
+			children[*folder.ParentID] = append(children[*folder.ParentID], folder)
+		}
+	}
+
+	var total func(folder *repository.Folder) int
+	total = func(folder *repository.Folder) int {
+		sum := folder.ClickCount
+		for _, child := range children[folder.ID] {
+			sum += total(child)
+		}
+		folder.TotalClickCount = sum
+		return sum
+	}
+	for _, folder := range folders {
+		if folder.ParentID == nil {
+			total(folder)
+		}
+	}
+
+	return folders, nil
+}
+
+func (r *FolderRepository) Update(folder *repository.Folder) (*repository.Folder, error) {
+	tag, err := r.db.Exec(context.Background(),
+		`UPDATE folders SET name = $3, parent_id = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2`,
+		folder.ID, folder.UserID, folder.Name, folder.ParentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update folder: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, repository.ErrFolderNotFound
+	}
+
+	return folder, nil
+}
+
+// Delete removes the folder and its subfolders. Links inside them are kept
+// and moved out to the top level.
+func (r *FolderRepository) Delete(id, userID int) error {
+	tag, err := r.db.Exec(context.Background(),
+		"DELETE FROM folders WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrFolderNotFound
+	}
+
+	return nil
+}
+
+// IsDescendant reports whether folderID is ancestorID itself or lies
+// anywhere below it.
+func (r *FolderRepository) IsDescendant(folderID, ancestorID int) (bool, error) {
+	var found bool
+	err := r.db.QueryRow(context.Background(),
+		`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM folders WHERE id = $1
+			UNION ALL
+			SELECT f.id, f.parent_id FROM folders f
+			JOIN ancestors a ON f.id = a.parent_id
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = $2)`,
+		folderID, ancestorID,
+	).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("failed to check folder tree: %w", err)
+	}
+
+	return found, nil
+}
+
+func (r *FolderRepository) MoveLink(linkID int, folderID *int) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE short_links SET folder_id = $2, updated_at = NOW() WHERE id = $1", linkID, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to move link: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LinkHealthRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLinkHealthRepository(db *pgxpool.Pool) *LinkHealthRepository {
+	return &LinkHealthRepository{db: db}
+}
+
+// Save stores the latest check result. Failures are counted across checks
+// and the notification mark is cleared once the link is healthy again.
+func (r *LinkHealthRepository) Save(health *repository.LinkHealth) (*repository.LinkHealth, error) {
+	chain := health.RedirectChain
+	if chain == nil {
+		chain = []string{}
+	}
+
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO link_health (short_link_id, healthy, status_code, latency_ms, redirect_chain, error,
+			consecutive_failures, checked_at, url)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, NULLIF($6, ''), CASE WHEN $2 THEN 0 ELSE 1 END, NOW(), $7)
+		ON CONFLICT (short_link_id) DO UPDATE SET
+			url = EXCLUDED.url,
+			healthy = EXCLUDED.healthy,
+			status_code = EXCLUDED.status_code,
+			latency_ms = EXCLUDED.latency_ms,
+			redirect_chain = EXCLUDED.redirect_chain,
+			error = EXCLUDED.error,
+			consecutive_failures = CASE WHEN EXCLUDED.healthy THEN 0
+				ELSE link_health.consecutive_failures + 1 END,
+			notified_at = CASE WHEN EXCLUDED.healthy THEN NULL ELSE link_health.notified_at END,
+			checked_at = EXCLUDED.checked_at
+		RETURNING consecutive_failures, notified_at, checked_at`,
+		health.LinkID, health.Healthy, health.StatusCode, health.LatencyMS, chain, health.Error, health.URL,
+	).Scan(&health.ConsecutiveFailures, &health.NotifiedAt, &health.CheckedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save link health: %w", err)
+	}
+
+	return health, nil
+}
+
+func (r *LinkHealthRepository) MarkNotified(linkID int) error {
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE link_health SET notified_at = NOW() WHERE short_link_id = $1", linkID)
+	if err != nil {
+		return fmt.Errorf("failed to mark link health notified: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+var linkSortColumns = map[string]string{
+	repository.LinkSortCreatedAt: "sl.created_at",
+	repository.LinkSortClicks:    "sl.clicks",
+	repository.LinkSortName:      "COALESCE(sl.name, '')",
+}
+
+// linkDestinationHostExpr extracts the lowercased host from the destination URL.
+const linkDestinationHostExpr = `lower(substring(sl.link from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^/@?#]*@)?([^/:?#]+)'))`
+
+func (r *LinkRepository) FindPage(q repository.LinkListQuery) (*repository.LinkPage, error) {
+	sortColumn, ok := linkSortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort: %s", q.Sort)
+	}
+
+	conditions := []string{"ul.user_id = $1", "sl.deleted_at IS NULL"}
+	args := []interface{}{q.UserID}
+
+	if q.CreatedFrom != nil {
+		args = append(args, *q.CreatedFrom)
+		conditions = append(conditions, fmt.Sprintf("sl.created_at >= $%d", len(args)))
+	}
+	if q.CreatedTo != nil {
+		args = append(args, *q.CreatedTo)
+		conditions = append(conditions, fmt.Sprintf("sl.created_at < $%d", len(args)))
+	}
+	if q.DestinationHost != "" {
+		args = append(args, strings.ToLower(q.DestinationHost))
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", linkDestinationHostExpr, len(args)))
+	}
+	if q.DomainID != nil {
+		args = append(args, *q.DomainID)
+		conditions = append(conditions, fmt.Sprintf("sl.domain_id = $%d", len(args)))
+	}
+	if q.Tag != "" {
+		args = append(args, q.Tag)
+		conditions = append(conditions, fmt.Sprintf(`EXISTS(
+			SELECT 1 FROM link_tags lt
+			JOIN tags t ON t.id = lt.tag_id
+			WHERE lt.short_link_id = sl.id AND lower(t.name) = lower($%d)
+		)`, len(args)))
+	}
+	if q.FolderID != nil {
+		args = append(args, *q.FolderID)
+		conditions = append(conditions, fmt.Sprintf("sl.folder_id = $%d", len(args)))
+	}
+
+	from := linkFrom + " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(context.Background(), "SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count links: %w", err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if q.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if q.After != nil {
+		var key interface{}
+		switch q.Sort {
+		case repository.LinkSortClicks:
+			key = q.After.Clicks
+		case repository.LinkSortName:
+			key = q.After.Name
+		default:
+			key = q.After.CreatedAt
+		}
+		args = append(args, key, q.After.ID)
+		from += fmt.Sprintf(" AND (%s, sl.id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args))
+	}
+
+	args = append(args, q.Limit+1)
+	query := "SELECT " + linkColumns + from +
+		fmt.Sprintf(" ORDER BY %s %s, sl.id %s LIMIT $%d", sortColumn, direction, direction, len(args))
+
+	rows, err := r.db.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer rows.Close()
+
+	page := &repository.LinkPage{Total: total}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+
+		if len(page.Links) == q.Limit {
+			last := page.Links[len(page.Links)-1]
+			page.Next = &repository.LinkCursor{
+				CreatedAt: last.CreatedAt,
+				Clicks:    last.ClickCount,
+				Name:      last.Title,
+				ID:        last.ID,
+			}
+			break
+		}
+		page.Links = append(page.Links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return page, nil
+}
+
+// Search combines the weighted full-text vector with trigram similarity, so
+// both whole words and URL fragments such as "exampl" or "/pricing" match.
+func (r *LinkRepository) Search(userID int, query string, limit int) ([]*repository.LinkSearchResult, error) {
+	sql := "SELECT " + linkColumns + `,
+			ts_rank(sl.search_vector, websearch_to_tsquery('simple', $2)) +
+				GREATEST(
+					similarity(sl.link, $2),
+					similarity(sl.short_link, $2),
+					similarity(COALESCE(sl.name, ''), $2),
+					similarity(COALESCE(sl.description, ''), $2),
+					similarity(COALESCE(sl.notes, ''), $2)
+				) AS rank
+		` + linkFrom + `
+		WHERE ul.user_id = $1 AND sl.deleted_at IS NULL
+		AND (
+			sl.search_vector @@ websearch_to_tsquery('simple', $2)
+			OR sl.link ILIKE $3
+			OR sl.short_link ILIKE $3
+			OR sl.name ILIKE $3
+			OR sl.description ILIKE $3
+			OR sl.notes ILIKE $3
+		)
+		ORDER BY rank DESC, sl.id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(context.Background(), sql, userID, query, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search links: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*repository.LinkSearchResult
+	for rows.Next() {
+		var rank float64
+		link, err := scanLink(rows, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		results = append(results, &repository.LinkSearchResult{Link: link, Rank: rank})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return results, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
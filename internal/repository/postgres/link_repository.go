@@ -11,6 +11,38 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// linkColumns is the select list shared by every link query; scanLink reads
+// it back in the same order.
+const linkColumns = `
+	sl.id, sl.link, sl.short_link, COALESCE(sl.name, ''), COALESCE(sl.description, ''),
+	COALESCE(sl.notes, ''), sl.clicks, sl.bot_clicks, sl.created_at, sl.deleted_at, ul.user_id, sl.folder_id, sl.interstitial,
+	sl.domain_id, COALESCE(dm.host, ''),
+	COALESCE(sl.utm_source, ''), COALESCE(sl.utm_medium, ''), COALESCE(sl.utm_campaign, ''),
+	COALESCE(sl.utm_term, ''), COALESCE(sl.utm_content, ''), sl.query_passthrough, sl.targeting_rules,
+	sl.variants, sl.schedule, sl.deep_link,
+	COALESCE(sl.disabled_reason, ''), sl.disabled_at, COALESCE(sl.threat_type, ''), sl.destination_threats,
+	lh.healthy, COALESCE(lh.url, ''), COALESCE(lh.status_code, 0), COALESCE(lh.latency_ms, 0), lh.redirect_chain,
+	COALESCE(lh.error, ''), COALESCE(lh.consecutive_failures, 0), lh.checked_at,
+	COALESCE((
+		SELECT jsonb_object_agg(cs.source, cs.clicks)
+		FROM link_click_sources cs
+		WHERE cs.short_link_id = sl.id
+	), '{}'),
+	COALESCE((
+		SELECT array_agg(t.name ORDER BY lower(t.name))
+		FROM link_tags lt
+		JOIN tags t ON t.id = lt.tag_id
+		WHERE lt.short_link_id = sl.id
+	), '{}')
+`
+
+const linkFrom = `
+	FROM short_links sl
+	JOIN user_links ul ON sl.id = ul.short_link_id
+	LEFT JOIN link_health lh ON lh.short_link_id = sl.id
+	LEFT JOIN domains dm ON dm.id = sl.domain_id
+`
+
 type LinkRepository struct {
 	db *pgxpool.Pool
 }
@@ -19,6 +51,68 @@ func NewLinkRepository(db *pgxpool.Pool) *LinkRepository {
 	return &LinkRepository{db: db}
 }
 
+// scanLink reads a row selected with linkColumns. Extra destinations are
+// scanned from any columns that follow.
+func scanLink(row pgx.Row, extra ...interface{}) (*repository.Link, error) {
+	var link repository.Link
+	var health repository.LinkHealth
+	var healthy *bool
+	var checkedAt *time.Time
+	dest := []interface{}{
+		&link.ID,
+		&link.OriginalURL,
+		&link.ShortCode,
+		&link.Title,
+		&link.Description,
+		&link.Notes,
+		&link.ClickCount,
+		&link.BotClickCount,
+		&link.CreatedAt,
+		&link.DeletedAt,
+		&link.UserID,
+		&link.FolderID,
+		&link.Interstitial,
+		&link.DomainID,
+		&link.Domain,
+		&link.UTMSource,
+		&link.UTMMedium,
+		&link.UTMCampaign,
+		&link.UTMTerm,
+		&link.UTMContent,
+		&link.QueryPassthrough,
+		&link.TargetingRules,
+		&link.Variants,
+		&link.Schedule,
+		&link.DeepLink,
+		&link.DisabledReason,
+		&link.DisabledAt,
+		&link.ThreatType,
+		&link.DestinationThreats,
+		&healthy,
+		&health.URL,
+		&health.StatusCode,
+		&health.LatencyMS,
+		&health.RedirectChain,
+		&health.Error,
+		&health.ConsecutiveFailures,
+		&checkedAt,
+		&link.ClickSources,
+		&link.Tags,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if healthy != nil && checkedAt != nil {
+		health.LinkID = link.ID
+		health.Healthy = *healthy
+		health.CheckedAt = *checkedAt
+		link.Health = &health
+	}
+
+	return &link, nil
+}
+
 func (r *LinkRepository) Create(link *repository.Link) (*repository.Link, error) {
 	ctx := context.Background()
 	tx, err := r.db.Begin(ctx)
@@ -29,11 +123,21 @@ func (r *LinkRepository) Create(link *repository.Link) (*repository.Link, error)
 
 	var shortLinkID int
 	err = tx.QueryRow(ctx,
-		`INSERT INTO short_links (link, short_link, clicks, created_at) 
-		VALUES ($1, $2, $3, $4) RETURNING id`,
-		link.OriginalURL, link.ShortCode, link.ClickCount, time.Now(),
+		`INSERT INTO short_links (link, short_link, name, description, notes, folder_id, interstitial,
+			utm_source, utm_medium, utm_campaign, utm_term, utm_content, query_passthrough,
+			threat_type, clicks, created_at, domain_id)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7,
+			NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''), $13,
+			NULLIF($14, ''), $15, $16, $17)
+		RETURNING id`,
+		link.OriginalURL, link.ShortCode, link.Title, link.Description, link.Notes, link.FolderID,
+		link.Interstitial, link.UTMSource, link.UTMMedium, link.UTMCampaign, link.UTMTerm, link.UTMContent,
+		link.QueryPassthrough, link.ThreatType, link.ClickCount, time.Now(), link.DomainID,
 	).Scan(&shortLinkID)
 	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrShortCodeTaken
+		}
 		return nil, fmt.Errorf("failed to create short link: %w", err)
 	}
 
@@ -45,229 +149,481 @@ func (r *LinkRepository) Create(link *repository.Link) (*repository.Link, error)
 		return nil, fmt.Errorf("failed to create user link: %w", err)
 	}
 
+	link.ID = shortLinkID
+	if err := insertVersion(ctx, tx, link); err != nil {
+		return nil, err
+	}
+
+	if len(link.Tags) > 0 {
+		if err := setLinkTags(ctx, tx, link.ID, link.UserID, link.Tags); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	link.ID = shortLinkID
 	return link, nil
 }
 
 func (r *LinkRepository) FindByID(id int) (*repository.Link, error) {
-	query := `
-		SELECT sl.id, sl.link, sl.short_link, sl.clicks, sl.created_at, ul.user_id
-		FROM short_links sl
-		JOIN user_links ul ON sl.id = ul.short_link_id
-		WHERE sl.id = $1
-	`
-	
-	var link repository.Link
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&link.ID,
-		&link.OriginalURL,
-		&link.ShortCode,
-		&link.ClickCount,
-		&link.CreatedAt,
-		&link.UserID,
-	)
-	
+	query := "SELECT " + linkColumns + linkFrom + " WHERE sl.id = $1 AND sl.deleted_at IS NULL"
+
+	link, err := scanLink(r.db.QueryRow(context.Background(), query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("link not found")
+			return nil, repository.ErrLinkNotFound
 		}
 		return nil, fmt.Errorf("failed to get link: %w", err)
 	}
-	
-	return &link, nil
+
+	return link, nil
 }
 
 func (r *LinkRepository) FindByUserID(userID int) ([]*repository.Link, error) {
-	query := `
-		SELECT sl.id, sl.link, sl.short_link, sl.clicks, sl.created_at, ul.user_id
-		FROM short_links sl
-		JOIN user_links ul ON sl.id = ul.short_link_id
-		WHERE ul.user_id = $1
+	var links []*repository.Link
+	err := r.EachByUserID(userID, func(link *repository.Link) error {
+		links = append(links, link)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// EachByUserID streams the user's links to fn one row at a time, so large
+// collections can be exported without loading them into memory.
+func (r *LinkRepository) EachByUserID(userID int, fn func(*repository.Link) error) error {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE ul.user_id = $1 AND sl.deleted_at IS NULL
 		ORDER BY sl.created_at DESC
 	`
-	
+
 	rows, err := r.db.Query(context.Background(), query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query links: %w", err)
+		return fmt.Errorf("failed to query links: %w", err)
 	}
 	defer rows.Close()
-	
-	var links []*repository.Link
+
 	for rows.Next() {
-		var link repository.Link
-		if err := rows.Scan(
-			&link.ID,
-			&link.OriginalURL,
-			&link.ShortCode,
-			&link.ClickCount,
-			&link.CreatedAt,
-			&link.UserID,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan link: %w", err)
+		link, err := scanLink(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan link: %w", err)
+		}
+		if err := fn(link); err != nil {
+			return err
 		}
-		links = append(links, &link)
 	}
-	
-	return links, nil
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
 }
 
-func (r *LinkRepository) FindByURLAndUser(url string, userID int) (*repository.Link, error) {
-	query := `
-		SELECT sl.id, sl.link, sl.short_link, sl.clicks, sl.created_at, ul.user_id
-		FROM short_links sl
-		JOIN user_links ul ON sl.id = ul.short_link_id
-		WHERE sl.link = $1 AND ul.user_id = $2
+func (r *LinkRepository) FindByURLAndUser(url string, userID int, domainID *int) (*repository.Link, error) {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE sl.link = $1 AND ul.user_id = $2 AND sl.domain_id IS NOT DISTINCT FROM $3
+			AND sl.deleted_at IS NULL
 		LIMIT 1
 	`
-	
-	var link repository.Link
-	err := r.db.QueryRow(context.Background(), query, url, userID).Scan(
-		&link.ID,
-		&link.OriginalURL,
-		&link.ShortCode,
-		&link.ClickCount,
-		&link.CreatedAt,
-		&link.UserID,
-	)
-	
+
+	link, err := scanLink(r.db.QueryRow(context.Background(), query, url, userID, domainID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to find link: %w", err)
 	}
-	
-	return &link, nil
+
+	return link, nil
 }
 
-func (r *LinkRepository) Update(id int, updates map[string]interface{}) (*repository.Link, error) {
+func (r *LinkRepository) Update(id int, update repository.LinkUpdate) (*repository.Link, error) {
 	tx, err := r.db.Begin(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(context.Background())
 
-	query := "UPDATE short_links SET "
+	// Column names are fixed here and never come from the request.
+	sets := []string{}
 	params := []interface{}{}
-	paramCount := 1
-
-	for field, value := range updates {
-		query += fmt.Sprintf("%s = $%d, ", field, paramCount)
+	set := func(column string, value interface{}) {
 		params = append(params, value)
-		paramCount++
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(params)))
 	}
 
-	query = query[:len(query)-2]
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, link, short_link, clicks, created_at", paramCount)
+	if update.OriginalURL != nil {
+		set("link", *update.OriginalURL)
+	}
+	if update.ShortCode != nil {
+		set("short_link", *update.ShortCode)
+	}
+	if update.Title != nil {
+		set("name", *update.Title)
+	}
+	if update.Description != nil {
+		set("description", *update.Description)
+	}
+	if update.Notes != nil {
+		set("notes", *update.Notes)
+	}
+	if update.Interstitial != nil {
+		set("interstitial", *update.Interstitial)
+	}
+	if update.UTMSource != nil {
+		set("utm_source", *update.UTMSource)
+	}
+	if update.UTMMedium != nil {
+		set("utm_medium", *update.UTMMedium)
+	}
+	if update.UTMCampaign != nil {
+		set("utm_campaign", *update.UTMCampaign)
+	}
+	if update.UTMTerm != nil {
+		set("utm_term", *update.UTMTerm)
+	}
+	if update.UTMContent != nil {
+		set("utm_content", *update.UTMContent)
+	}
+	if update.QueryPassthrough != nil {
+		set("query_passthrough", *update.QueryPassthrough)
+	}
+	if update.Routing != nil {
+		routing := *update.Routing
+		if routing.TargetingRules == nil {
+			routing.TargetingRules = []repository.TargetingRule{}
+		}
+		if routing.Variants == nil {
+			routing.Variants = []repository.LinkVariant{}
+		}
+		set("targeting_rules", routing.TargetingRules)
+		set("variants", routing.Variants)
+		set("schedule", routing.Schedule)
+		set("deep_link", routing.DeepLink)
+	}
+	sets = append(sets, "updated_at = NOW()")
 	params = append(params, id)
 
-	var link repository.Link
-	err = tx.QueryRow(context.Background(), query, params...).Scan(
-		&link.ID,
-		&link.OriginalURL,
-		&link.ShortCode,
-		&link.ClickCount,
-		&link.CreatedAt,
-	)
+	query := fmt.Sprintf("UPDATE short_links SET %s WHERE id = $%d", strings.Join(sets, ", "), len(params))
+
+	tag, err := tx.Exec(context.Background(), query, params...)
 	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrShortCodeTaken
+		}
 		return nil, fmt.Errorf("failed to update link: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return nil, repository.ErrLinkNotFound
+	}
 
-	err = tx.QueryRow(context.Background(),
-		"SELECT user_id FROM user_links WHERE short_link_id = $1", id,
-	).Scan(&link.UserID)
+	link, err := scanLink(tx.QueryRow(context.Background(),
+		"SELECT "+linkColumns+linkFrom+" WHERE sl.id = $1", id,
+	))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user_id: %w", err)
+		return nil, fmt.Errorf("failed to get updated link: %w", err)
+	}
+
+	if touchesVersionedFields(update) {
+		if err := insertVersion(context.Background(), tx, link); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return &link, nil
+	return link, nil
 }
 
+// Delete moves the link to the trash. Links that are already in the trash
+// are reported as not found.
 func (r *LinkRepository) Delete(id, userID int) error {
+	tag, err := r.db.Exec(context.Background(),
+		`UPDATE short_links SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		AND EXISTS(
+			SELECT 1 FROM user_links
+			WHERE short_link_id = $1 AND user_id = $2
+		)`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete link: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+func (r *LinkRepository) Find(filter map[string]interface{}) (*repository.Link, error) {
+	query := "SELECT " + linkColumns + linkFrom
+
+	conditions := []string{"sl.deleted_at IS NULL"}
+	var args []interface{}
+	argNum := 1
+
+	validFields := map[string]string{
+		"id":         "sl.id",
+		"link":       "sl.link",
+		"short_link": "sl.short_link",
+		"user_id":    "ul.user_id",
+	}
+
+	for field, value := range filter {
+		dbField, ok := validFields[field]
+		if !ok {
+			continue
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", dbField, argNum))
+		args = append(args, value)
+		argNum++
+	}
+
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	query += " LIMIT 1"
+
+	link, err := scanLink(r.db.QueryRow(context.Background(), query, args...))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find link: %w", err)
+	}
+
+	return link, nil
+}
+
+// FindByShortCode returns the active link with the short code on the domain,
+// or nil when there is none. A nil domainID means the service's own host.
+func (r *LinkRepository) FindByShortCode(domainID *int, shortCode string) (*repository.Link, error) {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE sl.short_link = $1 AND sl.domain_id IS NOT DISTINCT FROM $2 AND sl.deleted_at IS NULL
+		LIMIT 1
+	`
+
+	link, err := scanLink(r.db.QueryRow(context.Background(), query, shortCode, domainID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find link: %w", err)
+	}
+
+	return link, nil
+}
+
+// ShortCodeExists reports whether the short code is taken on the domain by
+// any link, including links that are sitting in the trash.
+func (r *LinkRepository) ShortCodeExists(domainID *int, shortCode string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(context.Background(),
 		`SELECT EXISTS(
-			SELECT 1 FROM user_links 
-			WHERE short_link_id = $1 AND user_id = $2
-		)`, id, userID).Scan(&exists)
-	
+			SELECT 1 FROM short_links WHERE short_link = $1 AND domain_id IS NOT DISTINCT FROM $2
+		)`, shortCode, domainID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check short code: %w", err)
+	}
+
+	return exists, nil
+}
+
+// SetTitleIfEmpty stores a title fetched from the destination page unless
+// the owner has set one in the meantime. It is not recorded as a version.
+func (r *LinkRepository) SetTitleIfEmpty(id int, title string) error {
+	_, err := r.db.Exec(context.Background(),
+		`UPDATE short_links SET name = $2
+		WHERE id = $1 AND (name IS NULL OR name = '')`, id, title)
+	if err != nil {
+		return fmt.Errorf("failed to set link title: %w", err)
+	}
+
+	return nil
+}
+
+// FindActiveAfter returns up to limit links with an id greater than afterID,
+// ordered by id, skipping disabled links and links in the trash. Callers
+// page through all links by passing the last id they got.
+func (r *LinkRepository) FindActiveAfter(afterID, limit int) ([]*repository.Link, error) {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE sl.id > $1 AND sl.deleted_at IS NULL AND sl.disabled_reason IS NULL
+		ORDER BY sl.id
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
 	if err != nil {
-		return fmt.Errorf("failed to check link ownership: %w", err)
+		return nil, fmt.Errorf("failed to query links: %w", err)
 	}
-	
-	if !exists {
-		return fmt.Errorf("link not found or access denied")
+	defer rows.Close()
+
+	var links []*repository.Link
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
 	}
 
-	_, err = r.db.Exec(context.Background(),
-		"DELETE FROM short_links WHERE id = $1", id)
-	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return links, nil
+}
+
+// EachLink streams every link that is not in the trash, across all users.
+func (r *LinkRepository) EachLink(fn func(*repository.Link) error) error {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE sl.deleted_at IS NULL
+		ORDER BY sl.id
+	`
+
+	rows, err := r.db.Query(context.Background(), query)
 	if err != nil {
-		return fmt.Errorf("failed to delete link: %w", err)
+		return fmt.Errorf("failed to query links: %w", err)
 	}
-	
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan link: %w", err)
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
 	return nil
 }
 
-func (r *LinkRepository) Find(filter map[string]interface{}) (*repository.Link, error) {
-    query := `
-        SELECT sl.id, sl.link, sl.short_link, sl.clicks, sl.created_at, ul.user_id
-        FROM short_links sl
-        JOIN user_links ul ON sl.id = ul.short_link_id
-    `
-
-    var conditions []string
-    var args []interface{}
-    argNum := 1
-
-    validFields := map[string]string{
-        "id":          "sl.id",
-        "link":        "sl.link",
-        "short_link":  "sl.short_link",
-        "user_id":     "ul.user_id",
-    }
-
-    for field, value := range filter {
-        dbField, ok := validFields[field]
-        if !ok {
-            continue
-        }
-
-        conditions = append(conditions, fmt.Sprintf("%s = $%d", dbField, argNum))
-        args = append(args, value)
-        argNum++
-    }
-
-    if len(conditions) > 0 {
-        query += " WHERE " + strings.Join(conditions, " AND ")
-    }
-
-    query += " LIMIT 1"
-
-    var link repository.Link
-    err := r.db.QueryRow(context.Background(), query, args...).Scan(
-        &link.ID,
-        &link.OriginalURL,
-        &link.ShortCode,
-        &link.ClickCount,
-        &link.CreatedAt,
-        &link.UserID,
-    )
-
-    if err != nil {
-        if err == pgx.ErrNoRows {
-            return nil, nil
-        }
-        return nil, fmt.Errorf("failed to find link: %w", err)
-    }
-
-    return &link, nil
-}
\ No newline at end of file
+// SetDisabled disables the link for the given reason, or enables it again
+// when reason is empty.
+func (r *LinkRepository) SetDisabled(id int, reason string) error {
+	_, err := r.db.Exec(context.Background(),
+		`UPDATE short_links SET
+			disabled_reason = NULLIF($2, ''),
+			disabled_at = CASE WHEN $2 = '' THEN NULL ELSE NOW() END
+		WHERE id = $1`, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to set link state: %w", err)
+	}
+
+	return nil
+}
+
+// SetThreat records the result of a threat scan. An empty threat type marks
+// the destination as clean.
+func (r *LinkRepository) SetThreat(id int, threatType string) error {
+	_, err := r.db.Exec(context.Background(),
+		`UPDATE short_links SET threat_type = NULLIF($2, ''), threat_checked_at = NOW()
+		WHERE id = $1`, id, threatType)
+	if err != nil {
+		return fmt.Errorf("failed to set link threat: %w", err)
+	}
+
+	return nil
+}
+
+// SetDestinationThreats replaces the threat types recorded for the link's
+// other destinations.
+func (r *LinkRepository) SetDestinationThreats(id int, threats map[string]string) error {
+	if threats == nil {
+		threats = map[string]string{}
+	}
+
+	_, err := r.db.Exec(context.Background(),
+		"UPDATE short_links SET destination_threats = $2, threat_checked_at = NOW() WHERE id = $1", id, threats)
+	if err != nil {
+		return fmt.Errorf("failed to set destination threats: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkRepository) SetTargetingRules(id int, rules []repository.TargetingRule) error {
+	if rules == nil {
+		rules = []repository.TargetingRule{}
+	}
+
+	if err := r.setVersioned(id, "targeting_rules", rules); err != nil {
+		return fmt.Errorf("failed to set targeting rules: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkRepository) SetVariants(id int, variants []repository.LinkVariant) error {
+	if variants == nil {
+		variants = []repository.LinkVariant{}
+	}
+
+	if err := r.setVersioned(id, "variants", variants); err != nil {
+		return fmt.Errorf("failed to set variants: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkRepository) SetSchedule(id int, schedule *repository.LinkSchedule) error {
+	if err := r.setVersioned(id, "schedule", schedule); err != nil {
+		return fmt.Errorf("failed to set schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkRepository) SetDeepLink(id int, deepLink *repository.DeepLink) error {
+	if err := r.setVersioned(id, "deep_link", deepLink); err != nil {
+		return fmt.Errorf("failed to set deep link: %w", err)
+	}
+
+	return nil
+}
+
+// setVersioned writes one routing column and records the resulting settings
+// as a new link version in the same transaction.
+func (r *LinkRepository) setVersioned(id int, column string, value interface{}) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE short_links SET "+column+" = $2, updated_at = NOW() WHERE id = $1", id, value)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrLinkNotFound
+	}
+
+	link, err := scanLink(tx.QueryRow(ctx, "SELECT "+linkColumns+linkFrom+" WHERE sl.id = $1", id))
+	if err != nil {
+		return err
+	}
+	if err := insertVersion(ctx, tx, link); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+func (r *LinkRepository) FindDeletedByUserID(userID int) ([]*repository.Link, error) {
+	query := "SELECT " + linkColumns + linkFrom + `
+		WHERE ul.user_id = $1 AND sl.deleted_at IS NOT NULL
+		ORDER BY sl.deleted_at DESC
+	`
+
+	rows, err := r.db.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*repository.Link
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return links, nil
+}
+
+func (r *LinkRepository) Restore(id, userID int) error {
+	tag, err := r.db.Exec(context.Background(),
+		`UPDATE short_links SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		AND EXISTS(
+			SELECT 1 FROM user_links
+			WHERE short_link_id = $1 AND user_id = $2
+		)`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore link: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrLinkNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes links that were soft deleted before the
+// given moment. Related rows are removed by ON DELETE CASCADE.
+func (r *LinkRepository) PurgeDeleted(before time.Time) (int64, error) {
+	tag, err := r.db.Exec(context.Background(),
+		"DELETE FROM short_links WHERE deleted_at IS NOT NULL AND deleted_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted links: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
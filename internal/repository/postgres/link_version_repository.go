@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const linkVersionColumns = `
+	id, short_link_id, version, link, short_link, COALESCE(name, ''), COALESCE(description, ''), created_at,
+	settings
+`
+
+// touchesVersionedFields reports whether the update changes a field that is
+// recorded in link_versions. Click counters, private notes and timestamps
+// are not.
+func touchesVersionedFields(update repository.LinkUpdate) bool {
+	return update.OriginalURL != nil || update.ShortCode != nil ||
+		update.Title != nil || update.Description != nil || update.Interstitial != nil ||
+		update.UTMSource != nil || update.UTMMedium != nil || update.UTMCampaign != nil ||
+		update.UTMTerm != nil || update.UTMContent != nil || update.QueryPassthrough != nil ||
+		update.Routing != nil
+}
+
+func insertVersion(ctx context.Context, tx pgx.Tx, link *repository.Link) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO link_versions (short_link_id, version, link, short_link, name, description, settings)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6
+		FROM link_versions WHERE short_link_id = $1`,
+		link.ID, link.OriginalURL, link.ShortCode, link.Title, link.Description, link.Settings(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create link version: %w", err)
+	}
+	return nil
+}
+
+func scanLinkVersion(row pgx.Row) (*repository.LinkVersion, error) {
+	var v repository.LinkVersion
+	if err := row.Scan(
+		&v.ID,
+		&v.LinkID,
+		&v.Version,
+		&v.OriginalURL,
+		&v.ShortCode,
+		&v.Title,
+		&v.Description,
+		&v.CreatedAt,
+		&v.Settings,
+	); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *LinkRepository) FindVersions(linkID int) ([]*repository.LinkVersion, error) {
+	query := "SELECT " + linkVersionColumns + `
+		FROM link_versions
+		WHERE short_link_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.Query(context.Background(), query, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query link versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*repository.LinkVersion
+	for rows.Next() {
+		version, err := scanLinkVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan link version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *LinkRepository) FindVersion(linkID, version int) (*repository.LinkVersion, error) {
+	query := "SELECT " + linkVersionColumns + `
+		FROM link_versions
+		WHERE short_link_id = $1 AND version = $2
+	`
+
+	v, err := scanLinkVersion(r.db.QueryRow(context.Background(), query, linkID, version))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, repository.ErrVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get link version: %w", err)
+	}
+
+	return v, nil
+}
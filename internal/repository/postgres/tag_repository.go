@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(userID int, name string) (*repository.Tag, error) {
+	var tag repository.Tag
+	err := r.db.QueryRow(context.Background(),
+		`INSERT INTO tags (user_id, name) VALUES ($1, $2)
+		RETURNING id, user_id, name, created_at`,
+		userID, name,
+	).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrTagExists
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepository) FindByUserID(userID int) ([]*repository.Tag, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.created_at,
+			COUNT(sl.id), COALESCE(SUM(sl.clicks), 0)
+		FROM tags t
+		LEFT JOIN link_tags lt ON lt.tag_id = t.id
+		LEFT JOIN short_links sl ON sl.id = lt.short_link_id AND sl.deleted_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY lower(t.name)
+	`
+
+	rows, err := r.db.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*repository.Tag
+	for rows.Next() {
+		var tag repository.Tag
+		if err := rows.Scan(
+			&tag.ID,
+			&tag.UserID,
+			&tag.Name,
+			&tag.CreatedAt,
+			&tag.LinkCount,
+			&tag.ClickCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) Rename(id, userID int, name string) (*repository.Tag, error) {
+	var tag repository.Tag
+	err := r.db.QueryRow(context.Background(),
+		`UPDATE tags SET name = $3 WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, name, created_at`,
+		id, userID, name,
+	).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrTagNotFound
+		}
+		if isDuplicateKeyError(err) {
+			return nil, repository.ErrTagExists
+		}
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepository) Delete(id, userID int) error {
+	tag, err := r.db.Exec(context.Background(),
+		"DELETE FROM tags WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repository.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// SetLinkTags replaces the link's tags with the given names, creating any
+// tags the user does not have yet.
+func (r *TagRepository) SetLinkTags(linkID, userID int, names []string) error {
+	ctx := context.Background()
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setLinkTags(ctx, tx, linkID, userID, names); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// setLinkTags replaces the link's tags inside tx, creating the user's tags
+// that do not exist yet.
+func setLinkTags(ctx context.Context, tx pgx.Tx, linkID, userID int, names []string) error {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	if len(names) > 0 {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO tags (user_id, name)
+			SELECT $1, unnest($2::text[])
+			ON CONFLICT (user_id, lower(name)) DO NOTHING`,
+			userID, names,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create tags: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM link_tags WHERE short_link_id = $1", linkID); err != nil {
+		return fmt.Errorf("failed to clear link tags: %w", err)
+	}
+
+	if len(names) > 0 {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO link_tags (short_link_id, tag_id)
+			SELECT $1, id FROM tags
+			WHERE user_id = $2 AND lower(name) = ANY($3::text[])`,
+			linkID, userID, lowered,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to tag link: %w", err)
+		}
+	}
+
+	return nil
+}
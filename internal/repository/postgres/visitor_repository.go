@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/pkg/hyperloglog"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type VisitorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVisitorRepository(db *pgxpool.Pool) *VisitorRepository {
+	return &VisitorRepository{db: db}
+}
+
+// Record raises one register of the link's sketch for the day. The update
+// happens in place, so concurrent visits never lose each other's ranks, and
+// rows are only written when the rank grows.
+func (r *VisitorRepository) Record(linkID int, day time.Time, index int, rank byte) error {
+	_, err := r.db.Exec(context.Background(),
+		`INSERT INTO link_unique_visitors (short_link_id, day, registers)
+		VALUES ($1, $2, set_byte(decode(repeat('00', $5), 'hex'), $3, $4))
+		ON CONFLICT (short_link_id, day) DO UPDATE
+		SET registers = set_byte(link_unique_visitors.registers, $3, $4)
+		WHERE get_byte(link_unique_visitors.registers, $3) < $4`,
+		linkID, day, index, int(rank), hyperloglog.Registers)
+	if err != nil {
+		return fmt.Errorf("failed to record visitor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VisitorRepository) FindByLink(linkID int, from, to *time.Time) ([]*repository.DailyVisitors, error) {
+	rows, err := r.db.Query(context.Background(),
+		`SELECT short_link_id, day, registers
+		FROM link_unique_visitors
+		WHERE short_link_id = $1
+			AND ($2::timestamp IS NULL OR day >= $2::date)
+			AND ($3::timestamp IS NULL OR day < $3)
+		ORDER BY day`,
+		linkID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visitors: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*repository.DailyVisitors
+	for rows.Next() {
+		visitors := &repository.DailyVisitors{}
+		if err := rows.Scan(&visitors.LinkID, &visitors.Day, &visitors.Registers); err != nil {
+			return nil, fmt.Errorf("failed to scan visitors: %w", err)
+		}
+		days = append(days, visitors)
+	}
+
+	return days, rows.Err()
+}
+
+// Salt returns the salt of the day, storing candidate when the day has none
+// yet. Every instance of the service gets the same salt this way.
+func (r *VisitorRepository) Salt(day time.Time, candidate []byte) ([]byte, error) {
+	_, err := r.db.Exec(context.Background(),
+		"INSERT INTO visitor_salts (day, salt) VALUES ($1, $2) ON CONFLICT (day) DO NOTHING",
+		day, candidate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store visitor salt: %w", err)
+	}
+
+	var salt []byte
+	err = r.db.QueryRow(context.Background(),
+		"SELECT salt FROM visitor_salts WHERE day = $1", day,
+	).Scan(&salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visitor salt: %w", err)
+	}
+
+	return salt, nil
+}
+
+func (r *VisitorRepository) DeleteSaltsBefore(day time.Time) error {
+	_, err := r.db.Exec(context.Background(), "DELETE FROM visitor_salts WHERE day < $1", day)
+	if err != nil {
+		return fmt.Errorf("failed to delete visitor salts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "time"
+
+type TagRepository interface {
+	Create(userID int, name string) (*Tag, error)
+	FindByUserID(userID int) ([]*Tag, error)
+	Rename(id, userID int, name string) (*Tag, error)
+	Delete(id, userID int) error
+	SetLinkTags(linkID, userID int, names []string) error
+}
+
+// Tag is a user-scoped label. LinkCount and ClickCount are aggregated over
+// the tagged links that are not in the trash.
+type Tag struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	Name       string    `json:"name" db:"name"`
+	LinkCount  int       `json:"link_count"`
+	ClickCount int       `json:"click_count"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
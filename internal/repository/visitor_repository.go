@@ -0,0 +1,31 @@
+package repository
+
+import "time"
+
+type VisitorRepository interface {
+	Record(linkID int, day time.Time, index int, rank byte) error
+	FindByLink(linkID int, from, to *time.Time) ([]*DailyVisitors, error)
+	Salt(day time.Time, candidate []byte) ([]byte, error)
+	DeleteSaltsBefore(day time.Time) error
+}
+
+// DailyVisitors holds the HyperLogLog registers of a link's visitors on one
+// UTC day.
+type DailyVisitors struct {
+	LinkID    int       `json:"link_id" db:"short_link_id"`
+	Day       time.Time `json:"day" db:"day"`
+	Registers []byte    `json:"-" db:"registers"`
+}
+
+// VisitorStats estimates a link's unique visitors over a period and on each
+// of its days. A visitor returning on another day is counted again, as
+// visitor hashes change every day.
+type VisitorStats struct {
+	UniqueVisitors uint64                `json:"unique_visitors"`
+	Days           []*DailyVisitorsCount `json:"days"`
+}
+
+type DailyVisitorsCount struct {
+	Date           string `json:"date"`
+	UniqueVisitors uint64 `json:"unique_visitors"`
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+//go:embed bot_patterns.txt
+var defaultBotPatterns string
+
+// BotFilter tells visits made by bots, link unfurlers and prefetching
+// browsers from visits by people. Bots are still redirected; their clicks
+// are only counted apart from human ones.
+type BotFilter struct {
+	userAgents *regexp.Regexp
+}
+
+// NewBotFilter compiles the built-in User-Agent patterns together with those
+// of patternsFile, if set. The file has the same format as bot_patterns.txt:
+// one case-insensitive regular expression per line.
+func NewBotFilter(patternsFile string) (*BotFilter, error) {
+	patterns, err := parseBotPatterns(strings.NewReader(defaultBotPatterns))
+	if err != nil {
+		return nil, fmt.Errorf("built-in bot patterns: %w", err)
+	}
+
+	if patternsFile != "" {
+		file, err := os.Open(patternsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		extra, err := parseBotPatterns(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", patternsFile, err)
+		}
+		patterns = append(patterns, extra...)
+	}
+
+	userAgents, err := regexp.Compile("(?i)(?:" + strings.Join(patterns, ")|(?:") + ")")
+	if err != nil {
+		return nil, err
+	}
+	return &BotFilter{userAgents: userAgents}, nil
+}
+
+func parseBotPatterns(r io.Reader) ([]string, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		if _, err := regexp.Compile(text); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern: %w", line, err)
+		}
+		patterns = append(patterns, text)
+	}
+	return patterns, scanner.Err()
+}
+
+// IsBot reports whether the visit was not made by a person following the
+// link. Besides known User-Agents that covers HEAD requests, which only
+// check the link, and speculative loads marked by prefetch headers.
+func (f *BotFilter) IsBot(visit Visit) bool {
+	if visit.Method == http.MethodHead || isPrefetch(visit.Header) {
+		return true
+	}
+	return f != nil && f.userAgents.MatchString(strings.TrimSpace(visit.UserAgent))
+}
+
+// isPrefetch recognises the headers browsers send with prefetch and
+// prerender requests.
+func isPrefetch(header http.Header) bool {
+	for _, name := range []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"} {
+		value := strings.ToLower(header.Get(name))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "preview") ||
+			strings.Contains(value, "prerender") {
+			return true
+		}
+	}
+	return false
+}
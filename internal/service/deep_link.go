@@ -0,0 +1,135 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+var appSchemePattern = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+// blockedAppSchemes can run code in or read from the browser, and the web
+// schemes belong in IOSURL and AndroidURL instead.
+var blockedAppSchemes = map[string]bool{
+	"http":       true,
+	"https":      true,
+	"javascript": true,
+	"data":       true,
+	"vbscript":   true,
+	"file":       true,
+	"blob":       true,
+	"about":      true,
+}
+
+// SetDeepLink replaces the link's app targets. A nil deep link, or one
+// without any target, removes them. The universal link and app link go
+// through the same URL, domain and threat checks as the link itself.
+func (s *LinkService) SetDeepLink(id, userID int, deepLink *repository.DeepLink) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	deepLink, err := s.normalizeDeepLink(deepLink)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.SetDeepLink(id, deepLink); err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scanDestinations(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// normalizeDeepLink checks the store URLs and the app URL. A deep link with
+// nothing set is stored as nil.
+func (s *LinkService) normalizeDeepLink(deepLink *repository.DeepLink) (*repository.DeepLink, error) {
+	if deepLink == nil {
+		return nil, nil
+	}
+
+	normalized := *deepLink
+	var err error
+	if normalized.IOSURL != "" {
+		if normalized.IOSURL, err = s.checkDestination(normalized.IOSURL); err != nil {
+			return nil, err
+		}
+	}
+	if normalized.AndroidURL != "" {
+		if normalized.AndroidURL, err = s.checkDestination(normalized.AndroidURL); err != nil {
+			return nil, err
+		}
+	}
+	if normalized.AppURL, err = s.normalizeAppURL(normalized.AppURL); err != nil {
+		return nil, err
+	}
+
+	if normalized == (repository.DeepLink{}) {
+		return nil, nil
+	}
+	return &normalized, nil
+}
+
+func (s *LinkService) normalizeAppURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > s.urlPolicy.maxLength {
+		return "", ValidationError("url is too long")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return "", ValidationError("invalid app url")
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !appSchemePattern.MatchString(scheme) || blockedAppSchemes[scheme] {
+		return "", ValidationError("app url scheme is not allowed")
+	}
+	u.Scheme = scheme
+
+	return u.String(), nil
+}
+
+// AppURL returns the custom scheme URL to try before the web destination, or
+// an empty string when the visitor should be redirected right away. Only
+// phones and tablets can have the app installed.
+func (s *LinkService) AppURL(link *repository.Link, visit Visit) string {
+	if link.DeepLink == nil || link.DeepLink.AppURL == "" || scheduledPending(link.Schedule, time.Now()) {
+		return ""
+	}
+
+	device := ParseDevice(visit.UserAgent)
+	if device.OS != "ios" && device.OS != "android" || device.Device == "bot" {
+		return ""
+	}
+	return link.DeepLink.AppURL
+}
+
+// deepLinkURL returns the universal link or app link for the visitor's
+// platform.
+func deepLinkURL(link *repository.Link, visit Visit) string {
+	if link.DeepLink == nil || link.DeepLink.IOSURL == "" && link.DeepLink.AndroidURL == "" {
+		return ""
+	}
+
+	switch ParseDevice(visit.UserAgent).OS {
+	case "ios":
+		return link.DeepLink.IOSURL
+	case "android":
+		return link.DeepLink.AndroidURL
+	}
+	return ""
+}
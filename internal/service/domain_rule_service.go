@@ -0,0 +1,86 @@
+package service
+
+import (
+	"log"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+type DomainRuleServiceInterface interface {
+	GetRules() ([]*repository.DomainRule, error)
+	AddRule(action, pattern string) (*repository.DomainRule, error)
+	DeleteRule(id int) error
+}
+
+// DomainRuleService manages the block and allow rules admins keep in the
+// database. Changes apply to new links right away, and the rescanner is
+// asked to apply them to existing links.
+type DomainRuleService struct {
+	ruleRepo  repository.DomainRuleRepository
+	filter    *DomainFilter
+	rescanner *DomainRescanner
+}
+
+func NewDomainRuleService(
+	ruleRepo repository.DomainRuleRepository,
+	filter *DomainFilter,
+	rescanner *DomainRescanner,
+) *DomainRuleService {
+	return &DomainRuleService{
+		ruleRepo:  ruleRepo,
+		filter:    filter,
+		rescanner: rescanner,
+	}
+}
+
+func (s *DomainRuleService) GetRules() ([]*repository.DomainRule, error) {
+	rules, err := s.ruleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*repository.DomainRule{}
+	}
+	return rules, nil
+}
+
+func (s *DomainRuleService) AddRule(action, pattern string) (*repository.DomainRule, error) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	if action != repository.DomainRuleBlock && action != repository.DomainRuleAllow {
+		return nil, ValidationError("invalid rule action")
+	}
+	pattern, err := normalizeRulePattern(pattern)
+	if err != nil {
+		return nil, ValidationError("invalid domain")
+	}
+
+	rule, err := s.ruleRepo.Create(&repository.DomainRule{Action: action, Pattern: pattern})
+	if err != nil {
+		return nil, err
+	}
+
+	s.apply()
+	return rule, nil
+}
+
+func (s *DomainRuleService) DeleteRule(id int) error {
+	if err := s.ruleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.apply()
+	return nil
+}
+
+// apply reloads the filter for new links and triggers a rescan for existing
+// ones. The change is already stored, so a failed reload is only logged;
+// the next rescan retries it.
+func (s *DomainRuleService) apply() {
+	if err := s.filter.Reload(); err != nil {
+		log.Printf("Failed to reload domain rules: %v", err)
+	}
+	if s.rescanner != nil {
+		s.rescanner.Trigger()
+	}
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"golang.org/x/net/idna"
+)
+
+// DomainRules decides which destination domains links may point to.
+//
+// The rules file has one rule per line:
+//
+//	block example.com      the domain itself
+//	block *.example.com    any subdomain of example.com
+//	allow example.org      once any allow rule exists, only allowed domains pass
+//	phishing.example       a bare domain is a block rule, so plain feeds work as is
+//
+// Blank lines and text after "#" are ignored. Block rules win over allow rules.
+// Admins can add rules of the same form through the API; they are applied
+// together with the file.
+type DomainRules struct {
+	blocked domainSet
+	allowed domainSet
+}
+
+type domainSet struct {
+	exact     map[string]bool
+	wildcards map[string]bool
+}
+
+func newDomainSet() domainSet {
+	return domainSet{exact: map[string]bool{}, wildcards: map[string]bool{}}
+}
+
+func (s domainSet) empty() bool {
+	return len(s.exact) == 0 && len(s.wildcards) == 0
+}
+
+func (s domainSet) add(pattern string) error {
+	pattern, err := normalizeRulePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		s.wildcards[domain] = true
+	} else {
+		s.exact[pattern] = true
+	}
+	return nil
+}
+
+func (s domainSet) copyTo(other domainSet) {
+	for domain := range s.exact {
+		other.exact[domain] = true
+	}
+	for domain := range s.wildcards {
+		other.wildcards[domain] = true
+	}
+}
+
+// normalizeRulePattern lowercases the pattern and converts its domain to
+// punycode, keeping a leading "*.".
+func normalizeRulePattern(pattern string) (string, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	domain, wildcard := strings.CutPrefix(pattern, "*.")
+	domain, err := idna.Lookup.ToASCII(domain)
+	if err != nil || domain == "" {
+		return "", fmt.Errorf("invalid domain %q", pattern)
+	}
+
+	if wildcard {
+		return "*." + domain, nil
+	}
+	return domain, nil
+}
+
+func (s domainSet) matches(host string) bool {
+	if s.exact[host] {
+		return true
+	}
+	for domain := host; ; {
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return false
+		}
+		domain = domain[i+1:]
+		if s.wildcards[domain] {
+			return true
+		}
+	}
+}
+
+func newDomainRules() *DomainRules {
+	return &DomainRules{blocked: newDomainSet(), allowed: newDomainSet()}
+}
+
+// add adds a rule with the given action, repository.DomainRuleBlock or
+// repository.DomainRuleAllow.
+func (r *DomainRules) add(action, pattern string) error {
+	switch action {
+	case repository.DomainRuleBlock:
+		return r.blocked.add(pattern)
+	case repository.DomainRuleAllow:
+		return r.allowed.add(pattern)
+	}
+	return fmt.Errorf("invalid rule action %q", action)
+}
+
+func (r *DomainRules) clone() *DomainRules {
+	rules := newDomainRules()
+	r.blocked.copyTo(rules.blocked)
+	r.allowed.copyTo(rules.allowed)
+	return rules
+}
+
+// ParseDomainRules reads rules in the format described on DomainRules.
+func ParseDomainRules(r io.Reader) (*DomainRules, error) {
+	rules := newDomainRules()
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(strings.ToLower(text))
+
+		var action, pattern string
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 1:
+			action, pattern = repository.DomainRuleBlock, fields[0]
+		case len(fields) == 2 && (fields[0] == repository.DomainRuleBlock || fields[0] == repository.DomainRuleAllow):
+			action, pattern = fields[0], fields[1]
+		default:
+			return nil, fmt.Errorf("line %d: invalid rule", line)
+		}
+
+		if err := rules.add(action, pattern); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Check fails when the URL's domain is blocked or, in allow-only mode, not
+// allowed. The URL is expected to be normalized by URLPolicy already.
+func (r *DomainRules) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ValidationError("invalid url")
+	}
+	host := strings.TrimSuffix(u.Hostname(), ".")
+
+	if r.blocked.matches(host) {
+		return ErrDomainBlocked
+	}
+	if !r.allowed.empty() && !r.allowed.matches(host) {
+		return ErrDomainNotAllowed
+	}
+	return nil
+}
+
+// DomainFilter holds the rules loaded from a file and from the rule store,
+// and picks up changes to both on Reload. A filter without rules lets every
+// domain through.
+type DomainFilter struct {
+	path  string
+	store repository.DomainRuleRepository
+
+	// reloadMu serializes reloads; fileRules and modTime are only used
+	// under it.
+	reloadMu  sync.Mutex
+	fileRules *DomainRules
+	modTime   time.Time
+
+	mu    sync.RWMutex
+	rules *DomainRules
+}
+
+// NewDomainFilter creates a filter reading the file at path and the rules in
+// store. Either may be empty or nil.
+func NewDomainFilter(path string, store repository.DomainRuleRepository) *DomainFilter {
+	return &DomainFilter{path: path, store: store}
+}
+
+// Reload re-reads the rules file if it changed since the last load, and the
+// stored rules. On error the previously loaded rules stay in effect.
+func (f *DomainFilter) Reload() error {
+	f.reloadMu.Lock()
+	defer f.reloadMu.Unlock()
+
+	if err := f.reloadFile(); err != nil {
+		return err
+	}
+
+	rules := newDomainRules()
+	if f.fileRules != nil {
+		rules = f.fileRules.clone()
+	}
+	if f.store != nil {
+		stored, err := f.store.FindAll()
+		if err != nil {
+			return fmt.Errorf("failed to load stored domain rules: %w", err)
+		}
+		for _, rule := range stored {
+			if err := rules.add(rule.Action, rule.Pattern); err != nil {
+				return fmt.Errorf("failed to load domain rule %d: %w", rule.ID, err)
+			}
+		}
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *DomainFilter) reloadFile() error {
+	if f.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat domain rules: %w", err)
+	}
+	if f.fileRules != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to open domain rules: %w", err)
+	}
+	defer file.Close()
+
+	rules, err := ParseDomainRules(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse domain rules: %w", err)
+	}
+
+	f.fileRules = rules
+	f.modTime = info.ModTime()
+	return nil
+}
+
+func (f *DomainFilter) Check(rawURL string) error {
+	f.mu.RLock()
+	rules := f.rules
+	f.mu.RUnlock()
+
+	if rules == nil {
+		return nil
+	}
+	return rules.Check(rawURL)
+}
+
+// DomainRescanner periodically reloads the domain rules and applies them to
+// existing links: links with a destination on a domain that became blocked
+// are disabled, and links it disabled earlier are enabled again once all
+// their domains are allowed.
+type DomainRescanner struct {
+	linkRepo repository.LinkRepository
+	filter   *DomainFilter
+	interval time.Duration
+	trigger  chan struct{}
+}
+
+func NewDomainRescanner(linkRepo repository.LinkRepository, filter *DomainFilter, interval time.Duration) *DomainRescanner {
+	return &DomainRescanner{
+		linkRepo: linkRepo,
+		filter:   filter,
+		interval: interval,
+		trigger:  make(chan struct{}, 1),
+	}
+}
+
+// Run rescans links every interval, and whenever Trigger is called, until
+// ctx is cancelled.
+func (s *DomainRescanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.rescan()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+	}
+}
+
+// Trigger asks Run to rescan now instead of waiting for the next tick.
+func (s *DomainRescanner) Trigger() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *DomainRescanner) rescan() {
+	if err := s.filter.Reload(); err != nil {
+		log.Printf("Failed to reload domain rules: %v", err)
+	}
+
+	var disabled, enabled int
+	err := s.linkRepo.EachLink(func(link *repository.Link) error {
+		blocked := false
+		for _, url := range linkDestinations(link) {
+			if s.filter.Check(url) != nil {
+				blocked = true
+				break
+			}
+		}
+
+		switch {
+		case blocked && link.DisabledReason == "":
+			if err := s.linkRepo.SetDisabled(link.ID, repository.LinkDisabledBlockedDomain); err != nil {
+				return err
+			}
+			disabled++
+		case !blocked && link.DisabledReason == repository.LinkDisabledBlockedDomain:
+			if err := s.linkRepo.SetDisabled(link.ID, ""); err != nil {
+				return err
+			}
+			enabled++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to rescan link domains: %v", err)
+	}
+	if disabled > 0 || enabled > 0 {
+		log.Printf("Domain rescan disabled %d and re-enabled %d links", disabled, enabled)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// memoryDomainRuleRepository keeps domain rules in memory.
+type memoryDomainRuleRepository struct {
+	rules  []*repository.DomainRule
+	nextID int
+}
+
+func (r *memoryDomainRuleRepository) Create(rule *repository.DomainRule) (*repository.DomainRule, error) {
+	for _, existing := range r.rules {
+		if existing.Action == rule.Action && existing.Pattern == rule.Pattern {
+			return nil, repository.ErrDomainRuleExists
+		}
+	}
+	r.nextID++
+	stored := *rule
+	stored.ID = r.nextID
+	r.rules = append(r.rules, &stored)
+	return &stored, nil
+}
+
+func (r *memoryDomainRuleRepository) FindAll() ([]*repository.DomainRule, error) {
+	return r.rules, nil
+}
+
+func (r *memoryDomainRuleRepository) Delete(id int) error {
+	for i, rule := range r.rules {
+		if rule.ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrDomainRuleNotFound
+}
+
+func TestDomainRuleService(t *testing.T) {
+	filter := NewDomainFilter("", &memoryDomainRuleRepository{})
+	if err := filter.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	s := NewDomainRuleService(filter.store, filter, nil)
+
+	if err := filter.Check("https://phish.example/"); err != nil {
+		t.Fatalf("no rules should let every domain through: %v", err)
+	}
+
+	rule, err := s.AddRule(" BLOCK ", "*.Phish.Example")
+	if err != nil {
+		t.Fatalf("AddRule: %v", err)
+	}
+	if rule.Action != "block" || rule.Pattern != "*.phish.example" {
+		t.Errorf("rule was not normalized: %+v", rule)
+	}
+	if err := filter.Check("https://login.phish.example/"); !errors.Is(err, ErrDomainBlocked) {
+		t.Errorf("Check after AddRule = %v, want ErrDomainBlocked", err)
+	}
+	if _, err := s.AddRule("block", "*.phish.example"); !errors.Is(err, repository.ErrDomainRuleExists) {
+		t.Errorf("duplicate rule error = %v, want ErrDomainRuleExists", err)
+	}
+
+	if err := s.DeleteRule(rule.ID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if err := filter.Check("https://login.phish.example/"); err != nil {
+		t.Errorf("Check after DeleteRule = %v, want nil", err)
+	}
+	if err := s.DeleteRule(rule.ID); !errors.Is(err, repository.ErrDomainRuleNotFound) {
+		t.Errorf("second DeleteRule error = %v, want ErrDomainRuleNotFound", err)
+	}
+}
+
+func TestDomainRuleServiceValidation(t *testing.T) {
+	filter := NewDomainFilter("", &memoryDomainRuleRepository{})
+	s := NewDomainRuleService(filter.store, filter, nil)
+
+	var invalid ValidationError
+	if _, err := s.AddRule("deny", "example.com"); !errors.As(err, &invalid) {
+		t.Errorf("unknown action error = %v, want a ValidationError", err)
+	}
+	if _, err := s.AddRule("block", " "); !errors.As(err, &invalid) {
+		t.Errorf("empty pattern error = %v, want a ValidationError", err)
+	}
+}
+
+func TestDomainFilterCombinesFileAndStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.txt")
+	if err := os.WriteFile(path, []byte("allow example.com\nallow example.org\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryDomainRuleRepository{}
+	store.Create(&repository.DomainRule{Action: "block", Pattern: "example.org"})
+
+	filter := NewDomainFilter(path, store)
+	if err := filter.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if err := filter.Check("https://example.com/"); err != nil {
+		t.Errorf("allowed domain: %v", err)
+	}
+	if err := filter.Check("https://example.org/"); !errors.Is(err, ErrDomainBlocked) {
+		t.Errorf("stored block rule should win over the file's allow rule: %v", err)
+	}
+	if err := filter.Check("https://example.net/"); !errors.Is(err, ErrDomainNotAllowed) {
+		t.Errorf("domain outside the allow list: %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"golang.org/x/net/idna"
+)
+
+// Custom domains are verified by a TXT record named DomainVerificationPrefix
+// plus the host, whose value is DomainVerificationValue plus the domain's
+// token.
+const (
+	DomainVerificationPrefix = "_shortlink."
+	DomainVerificationValue  = "shortlink-verification="
+)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver implements it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// StaticTXTResolver answers from a fixed set of records, so domains can be
+// verified without real DNS in development and tests.
+type StaticTXTResolver struct {
+	records map[string][]string
+}
+
+func NewStaticTXTResolver(records map[string][]string) *StaticTXTResolver {
+	normalized := map[string][]string{}
+	for name, values := range records {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		normalized[name] = append(normalized[name], values...)
+	}
+	return &StaticTXTResolver{records: normalized}
+}
+
+func (r *StaticTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	values, ok := r.records[strings.ToLower(strings.TrimSuffix(name, "."))]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return values, nil
+}
+
+type DomainServiceInterface interface {
+	GetDomains(userID int) ([]*repository.Domain, error)
+	AddDomain(userID int, host string) (*repository.Domain, error)
+	VerifyDomain(id, userID int) (*repository.Domain, error)
+	DeleteDomain(id, userID int) error
+	SetErrorPages(id, userID int, pages *repository.DomainErrorPages) (*repository.Domain, error)
+	ErrorPages(host string) (*repository.DomainErrorPages, error)
+}
+
+// Limits for the texts of a domain's error pages.
+const (
+	MaxErrorPageTitleLength   = 200
+	MaxErrorPageMessageLength = 2000
+)
+
+type DomainService struct {
+	domainRepo repository.DomainRepository
+	resolver   TXTResolver
+	timeout    time.Duration
+	ownHosts   []string
+	urlPolicy  *URLPolicy
+	domains    *DomainFilter
+	threats    ThreatScanner
+}
+
+// NewDomainService creates the service. ownHosts are the service's own hosts,
+// which cannot be registered as custom domains. The URL policy, domain rules
+// and threat scanner check error page redirects the same way as link
+// destinations; threats may be nil.
+func NewDomainService(
+	domainRepo repository.DomainRepository,
+	resolver TXTResolver,
+	timeout time.Duration,
+	ownHosts []string,
+	urlPolicy *URLPolicy,
+	domains *DomainFilter,
+	threats ThreatScanner,
+) *DomainService {
+	return &DomainService{
+		domainRepo: domainRepo,
+		resolver:   resolver,
+		timeout:    timeout,
+		ownHosts:   ownHosts,
+		urlPolicy:  urlPolicy,
+		domains:    domains,
+		threats:    threats,
+	}
+}
+
+func (s *DomainService) GetDomains(userID int) ([]*repository.Domain, error) {
+	domains, err := s.domainRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if domains == nil {
+		domains = []*repository.Domain{}
+	}
+	return domains, nil
+}
+
+// AddDomain registers an unverified domain for the user. Its links work once
+// VerifyDomain finds the TXT record.
+func (s *DomainService) AddDomain(userID int, host string) (*repository.Domain, error) {
+	host, err := normalizeDomainHost(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, own := range s.ownHosts {
+		if host == own {
+			return nil, ValidationError("invalid domain")
+		}
+	}
+
+	// Another user's verified domain has claimed the host already.
+	claimed, err := s.domainRepo.FindVerifiedByHost(host)
+	if err != nil {
+		return nil, err
+	}
+	if claimed != nil {
+		return nil, repository.ErrDomainExists
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	return s.domainRepo.Create(&repository.Domain{
+		UserID:            userID,
+		Host:              host,
+		VerificationToken: hex.EncodeToString(token),
+	})
+}
+
+// VerifyDomain looks up the domain's TXT record and marks the domain as
+// verified when it holds the expected token. The first registration of a
+// host to be verified claims it; the others fail with ErrDomainExists. A
+// verified domain stays verified.
+func (s *DomainService) VerifyDomain(id, userID int) (*repository.Domain, error) {
+	domain, err := s.getDomain(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerifiedAt != nil {
+		return domain, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	records, err := s.resolver.LookupTXT(ctx, DomainVerificationPrefix+domain.Host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, ErrVerificationRecordNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrVerificationLookup, err)
+	}
+
+	expected := DomainVerificationValue + domain.VerificationToken
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return s.domainRepo.MarkVerified(domain.ID)
+		}
+	}
+
+	return nil, ErrVerificationRecordNotFound
+}
+
+// DeleteDomain removes the domain. Domains that still have links, including
+// links in the trash, cannot be removed.
+func (s *DomainService) DeleteDomain(id, userID int) error {
+	if _, err := s.getDomain(id, userID); err != nil {
+		return err
+	}
+
+	hasLinks, err := s.domainRepo.HasLinks(id)
+	if err != nil {
+		return err
+	}
+	if hasLinks {
+		return ErrDomainHasLinks
+	}
+
+	return s.domainRepo.Delete(id, userID)
+}
+
+// SetErrorPages replaces the domain's error pages. nil restores the service's
+// defaults.
+func (s *DomainService) SetErrorPages(id, userID int, pages *repository.DomainErrorPages) (*repository.Domain, error) {
+	if _, err := s.getDomain(id, userID); err != nil {
+		return nil, err
+	}
+
+	if pages != nil {
+		var err error
+		if pages.FallbackURL, err = s.CheckRedirectURL(pages.FallbackURL); err != nil {
+			return nil, err
+		}
+		for _, page := range []*repository.ErrorPage{pages.NotFound, pages.Expired, pages.Disabled, pages.Blocked} {
+			if page == nil {
+				continue
+			}
+			page.Title = strings.TrimSpace(page.Title)
+			page.Message = strings.TrimSpace(page.Message)
+			if utf8.RuneCountInString(page.Title) > MaxErrorPageTitleLength {
+				return nil, ValidationError("error page title is too long")
+			}
+			if utf8.RuneCountInString(page.Message) > MaxErrorPageMessageLength {
+				return nil, ValidationError("error page message is too long")
+			}
+			if page.RedirectURL, err = s.CheckRedirectURL(page.RedirectURL); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.domainRepo.SetErrorPages(id, pages); err != nil {
+		return nil, err
+	}
+
+	return s.domainRepo.FindByID(id)
+}
+
+// ErrorPages returns the error pages of the verified domain serving the
+// request host, or nil when the host has none.
+func (s *DomainService) ErrorPages(host string) (*repository.DomainErrorPages, error) {
+	domain, err := s.domainRepo.FindVerifiedByHost(requestHost(host))
+	if err != nil || domain == nil {
+		return nil, err
+	}
+	return domain.ErrorPages, nil
+}
+
+func (s *DomainService) getDomain(id, userID int) (*repository.Domain, error) {
+	domain, err := s.domainRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.UserID != userID {
+		return nil, repository.ErrDomainNotFound
+	}
+
+	return domain, nil
+}
+
+// CheckRedirectURL validates a URL that visitors of missing or inactive
+// links are redirected to: a domain's error page redirect or fallback, or
+// ERROR_FALLBACK_URL. It must be an absolute http(s) URL that passes the
+// URL policy and the domain rules and is not flagged by the threat scanner.
+// It returns the normalized URL; an empty URL stays empty.
+func (s *DomainService) CheckRedirectURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ValidationError("invalid redirect url")
+	}
+
+	normalized, err := s.urlPolicy.Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if err := s.domains.Check(normalized); err != nil {
+		return "", err
+	}
+
+	// Unlike link destinations, redirects from error pages skip the warning
+	// page, so flagged URLs are rejected. Scanner failures are logged and
+	// the URL is accepted, as for links.
+	if s.threats != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), threatScanTimeout)
+		defer cancel()
+
+		threats, err := s.threats.Scan(ctx, []string{normalized})
+		if err != nil {
+			log.Printf("Failed to scan %s for threats: %v", normalized, err)
+		} else if threats[normalized] != "" {
+			return "", ErrURLFlagged
+		}
+	}
+
+	return normalized, nil
+}
+
+// normalizeDomainHost lowercases the host and converts it to its ASCII form.
+// Only plain host names with at least two labels are accepted.
+func normalizeDomainHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	if host == "" || net.ParseIP(host) != nil || strings.ContainsAny(host, ":/") {
+		return "", ValidationError("invalid domain")
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil || len(ascii) > 253 || !strings.Contains(ascii, ".") {
+		return "", ValidationError("invalid domain")
+	}
+
+	return ascii, nil
+}
+
+// requestHost returns the host of a request's Host header without the port,
+// in the form custom domains are stored in.
+func requestHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	return host
+}
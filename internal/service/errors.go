@@ -0,0 +1,30 @@
+package service
+
+import "errors"
+
+// Errors returned by the link and domain services. Handlers tell them apart
+// with errors.Is to pick the response status.
+var (
+	ErrAccessDenied      = errors.New("access denied")
+	ErrDomainNotVerified = errors.New("domain is not verified")
+	ErrDomainBlocked     = errors.New("domain is blocked")
+	ErrDomainNotAllowed  = errors.New("domain is not allowed")
+	ErrDomainHasLinks    = errors.New("domain has links")
+	ErrURLFlagged        = errors.New("url is flagged as unsafe")
+	ErrLinkExpired       = errors.New("link has expired")
+	ErrLinkNotActive     = errors.New("link is not active yet")
+	ErrLinkDisabled      = errors.New("link is disabled")
+	ErrLinkBlocked       = errors.New("link is blocked")
+
+	ErrVerificationRecordNotFound = errors.New("verification record not found")
+	// ErrVerificationLookup wraps DNS failures other than a missing record.
+	ErrVerificationLookup = errors.New("failed to look up verification record")
+)
+
+// ValidationError rejects invalid input. Its message is meant for the
+// client.
+type ValidationError string
+
+func (e ValidationError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+const MaxFolderNameLength = 255
+
+type FolderServiceInterface interface {
+	GetFolders(userID int) ([]*repository.Folder, error)
+	CreateFolder(userID int, name string, parentID *int) (*repository.Folder, error)
+	UpdateFolder(id, userID int, name string, parentID *int) (*repository.Folder, error)
+	DeleteFolder(id, userID int) error
+}
+
+type FolderService struct {
+	folderRepo repository.FolderRepository
+}
+
+func NewFolderService(folderRepo repository.FolderRepository) *FolderService {
+	return &FolderService{folderRepo: folderRepo}
+}
+
+func (s *FolderService) GetFolders(userID int) ([]*repository.Folder, error) {
+	folders, err := s.folderRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if folders == nil {
+		folders = []*repository.Folder{}
+	}
+	return folders, nil
+}
+
+func (s *FolderService) CreateFolder(userID int, name string, parentID *int) (*repository.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		if _, err := s.getFolder(*parentID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.folderRepo.Create(&repository.Folder{
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     name,
+	})
+}
+
+// UpdateFolder renames the folder and moves it under parentID. A folder
+// cannot be moved into itself or one of its own subfolders.
+func (s *FolderService) UpdateFolder(id, userID int, name string, parentID *int) (*repository.Folder, error) {
+	folder, err := s.getFolder(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if folder.Name, err = normalizeFolderName(name); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		if _, err := s.getFolder(*parentID, userID); err != nil {
+			return nil, err
+		}
+
+		cycle, err := s.folderRepo.IsDescendant(*parentID, id)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, ValidationError("folder cannot be moved into itself")
+		}
+	}
+	folder.ParentID = parentID
+
+	return s.folderRepo.Update(folder)
+}
+
+func (s *FolderService) DeleteFolder(id, userID int) error {
+	return s.folderRepo.Delete(id, userID)
+}
+
+func (s *FolderService) getFolder(id, userID int) (*repository.Folder, error) {
+	folder, err := s.folderRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if folder.UserID != userID {
+		return nil, repository.ErrFolderNotFound
+	}
+
+	return folder, nil
+}
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ValidationError("folder name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxFolderNameLength {
+		return "", ValidationError("folder name is too long")
+	}
+	return name, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoLocation is where a visitor comes from. Country is an ISO 3166-1 alpha-2
+// code and Region an ISO 3166-2 subdivision code such as "US-CA". Either may
+// be empty when the database does not know it.
+type GeoLocation struct {
+	Country string
+	Region  string
+}
+
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// GeoIPDatabase resolves IP addresses with a MaxMind-format database file,
+// such as GeoLite2-City or GeoIP2-Country. The file is read into memory and
+// replaced on Reload when it changes on disk, so it can be updated without a
+// restart. A database without a file resolves nothing.
+type GeoIPDatabase struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+func NewGeoIPDatabase(path string, interval time.Duration) *GeoIPDatabase {
+	return &GeoIPDatabase{path: path, interval: interval}
+}
+
+// Reload re-reads the database file if it changed since the last load. On
+// error the previously loaded database stays in effect.
+func (d *GeoIPDatabase) Reload() error {
+	if d.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat geoip database: %w", err)
+	}
+
+	d.mu.RLock()
+	unchanged := d.reader != nil && info.ModTime().Equal(d.modTime)
+	d.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to read geoip database: %w", err)
+	}
+
+	// Readers built from bytes hold no file handle, so the old one can be
+	// dropped while lookups may still be using it.
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to open geoip database: %w", err)
+	}
+
+	d.mu.Lock()
+	d.reader = reader
+	d.modTime = info.ModTime()
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Run reloads the database every interval until ctx is cancelled.
+func (d *GeoIPDatabase) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Reload(); err != nil {
+				log.Printf("Failed to reload geoip database: %v", err)
+			}
+		}
+	}
+}
+
+// Lookup returns the location of ip. Unknown and private addresses resolve to
+// an empty location.
+func (d *GeoIPDatabase) Lookup(ip net.IP) GeoLocation {
+	if d == nil || ip == nil {
+		return GeoLocation{}
+	}
+
+	d.mu.RLock()
+	reader := d.reader
+	d.mu.RUnlock()
+
+	if reader == nil {
+		return GeoLocation{}
+	}
+
+	var record geoIPRecord
+	if err := reader.Lookup(ip, &record); err != nil {
+		return GeoLocation{}
+	}
+
+	location := GeoLocation{Country: record.Country.ISOCode}
+	if location.Country != "" && len(record.Subdivisions) > 0 && record.Subdivisions[0].ISOCode != "" {
+		location.Region = location.Country + "-" + record.Subdivisions[0].ISOCode
+	}
+	return location
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// maxHealthRedirects is how many redirects a destination check follows.
+const maxHealthRedirects = 10
+
+// BrokenLinkNotifier tells a link owner that the link's destination keeps
+// failing health checks.
+type BrokenLinkNotifier interface {
+	NotifyBrokenLink(ctx context.Context, link *repository.Link, health *repository.LinkHealth) error
+}
+
+// healthCheckPageSize is how many links are loaded and checked at a time.
+const healthCheckPageSize = 500
+
+// HealthChecker periodically requests every destination of the links that
+// are neither disabled nor in the trash, and stores the status, latency and
+// redirect chain. Links are loaded page by page. At most workers hosts are
+// checked at once, and requests to the same host are made one by one with
+// hostDelay between them, across pages too.
+type HealthChecker struct {
+	linkRepo   repository.LinkRepository
+	healthRepo repository.LinkHealthRepository
+	notifier   BrokenLinkNotifier
+	client     *http.Client
+
+	interval  time.Duration
+	workers   int
+	hostDelay time.Duration
+	threshold int
+	pageSize  int
+
+	// lastRequest holds when each host was last requested. Hosts not
+	// requested within hostDelay are dropped between pages.
+	mu          sync.Mutex
+	lastRequest map[string]time.Time
+}
+
+// NewHealthChecker creates the checker. notifier may be nil; owners are then
+// not notified. A link is reported once it fails threshold checks in a row.
+func NewHealthChecker(
+	linkRepo repository.LinkRepository,
+	healthRepo repository.LinkHealthRepository,
+	notifier BrokenLinkNotifier,
+	interval, timeout, hostDelay time.Duration,
+	workers, threshold int,
+) *HealthChecker {
+	return &HealthChecker{
+		linkRepo:    linkRepo,
+		healthRepo:  healthRepo,
+		notifier:    notifier,
+		client:      newPublicHTTPClient(timeout),
+		interval:    interval,
+		workers:     workers,
+		hostDelay:   hostDelay,
+		threshold:   threshold,
+		pageSize:    healthCheckPageSize,
+		lastRequest: map[string]time.Time{},
+	}
+}
+
+// Run checks all links every interval until ctx is cancelled.
+func (c *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *HealthChecker) checkAll(ctx context.Context) {
+	afterID := 0
+	for ctx.Err() == nil {
+		links, err := c.linkRepo.FindActiveAfter(afterID, c.pageSize)
+		if err != nil {
+			log.Printf("Failed to load links for health check: %v", err)
+			return
+		}
+		if len(links) == 0 {
+			return
+		}
+
+		c.checkPage(ctx, links)
+		afterID = links[len(links)-1].ID
+	}
+}
+
+// destinationCheck is one destination of a link and, once checked, its
+// result.
+type destinationCheck struct {
+	url    string
+	health *repository.LinkHealth
+}
+
+func (c *HealthChecker) checkPage(ctx context.Context, links []*repository.Link) {
+	c.forgetIdleHosts()
+
+	checks := make([][]*destinationCheck, len(links))
+	byHost := map[string][]*destinationCheck{}
+	for i, link := range links {
+		seen := map[string]bool{}
+		for _, rawURL := range linkDestinations(link) {
+			if seen[rawURL] {
+				continue
+			}
+			seen[rawURL] = true
+
+			check := &destinationCheck{url: rawURL}
+			checks[i] = append(checks[i], check)
+			host := rawURL
+			if u, err := url.Parse(rawURL); err == nil {
+				host = u.Hostname()
+			}
+			byHost[host] = append(byHost[host], check)
+		}
+	}
+
+	hosts := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				c.checkHost(ctx, host, byHost[host])
+			}
+		}()
+	}
+
+	for host := range byHost {
+		select {
+		case hosts <- host:
+		case <-ctx.Done():
+		}
+	}
+	close(hosts)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	for i, link := range links {
+		health := linkHealth(checks[i])
+		health.LinkID = link.ID
+
+		saved, err := c.healthRepo.Save(health)
+		if err != nil {
+			log.Printf("Failed to save health of link %d: %v", link.ID, err)
+			continue
+		}
+
+		c.notify(ctx, link, saved)
+	}
+}
+
+// linkHealth picks the result stored for a link: the first failing
+// destination, or the link's own URL when all of them are healthy.
+func linkHealth(checks []*destinationCheck) *repository.LinkHealth {
+	for _, check := range checks {
+		if !check.health.Healthy {
+			return check.health
+		}
+	}
+	return checks[0].health
+}
+
+func (c *HealthChecker) checkHost(ctx context.Context, host string, checks []*destinationCheck) {
+	for _, check := range checks {
+		if !c.waitForHost(ctx, host) {
+			return
+		}
+
+		check.health = c.Check(ctx, check.url)
+		check.health.URL = check.url
+
+		c.mu.Lock()
+		c.lastRequest[host] = time.Now()
+		c.mu.Unlock()
+	}
+}
+
+// waitForHost waits until hostDelay has passed since the last request to
+// the host. It returns false if ctx is cancelled first.
+func (c *HealthChecker) waitForHost(ctx context.Context, host string) bool {
+	c.mu.Lock()
+	last, ok := c.lastRequest[host]
+	c.mu.Unlock()
+
+	if ok {
+		if wait := time.Until(last.Add(c.hostDelay)); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(wait):
+			}
+		}
+	}
+	return ctx.Err() == nil
+}
+
+func (c *HealthChecker) forgetIdleHosts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for host, last := range c.lastRequest {
+		if time.Since(last) >= c.hostDelay {
+			delete(c.lastRequest, host)
+		}
+	}
+}
+
+func (c *HealthChecker) notify(ctx context.Context, link *repository.Link, health *repository.LinkHealth) {
+	if c.notifier == nil || health.Healthy || health.NotifiedAt != nil ||
+		health.ConsecutiveFailures < c.threshold {
+		return
+	}
+
+	if err := c.notifier.NotifyBrokenLink(ctx, link, health); err != nil {
+		log.Printf("Failed to notify owner of broken link %d: %v", link.ID, err)
+		return
+	}
+	if err := c.healthRepo.MarkNotified(link.ID); err != nil {
+		log.Printf("Failed to mark link %d as notified: %v", link.ID, err)
+	}
+}
+
+// Check requests the URL with HEAD and falls back to GET when the server
+// rejects HEAD, since many servers answer it incorrectly. Destinations that
+// end in a 2xx or 3xx response are healthy.
+func (c *HealthChecker) Check(ctx context.Context, rawURL string) *repository.LinkHealth {
+	health := c.request(ctx, http.MethodHead, rawURL)
+	if !health.Healthy {
+		health = c.request(ctx, http.MethodGet, rawURL)
+	}
+	return health
+}
+
+func (c *HealthChecker) request(ctx context.Context, method, rawURL string) *repository.LinkHealth {
+	health := &repository.LinkHealth{RedirectChain: []string{}}
+
+	client := *c.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxHealthRedirects {
+			return errors.New("too many redirects")
+		}
+		health.RedirectChain = append(health.RedirectChain, req.URL.String())
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	req.Header.Set("User-Agent", "ShortLinkBot/1.0 (+link health check)")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	health.LatencyMS = int(time.Since(start).Milliseconds())
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	health.StatusCode = resp.StatusCode
+	health.Healthy = resp.StatusCode < http.StatusBadRequest
+	if !health.Healthy {
+		health.Error = resp.Status
+	}
+
+	return health
+}
+
+// WebhookNotifier posts broken link reports as JSON to a URL, where they
+// can be turned into emails or chat messages.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) NotifyBrokenLink(ctx context.Context, link *repository.Link, health *repository.LinkHealth) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":        "link.broken",
+		"user_id":      link.UserID,
+		"link_id":      link.ID,
+		"short_code":   link.ShortCode,
+		"original_url": link.OriginalURL,
+		"health":       health,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
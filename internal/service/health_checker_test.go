@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// memoryLinkHealthRepository keeps the latest health of each link.
+type memoryLinkHealthRepository struct {
+	mu     sync.Mutex
+	health map[int]*repository.LinkHealth
+}
+
+func (r *memoryLinkHealthRepository) Save(health *repository.LinkHealth) (*repository.LinkHealth, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *health
+	stored.CheckedAt = time.Now()
+	r.health[health.LinkID] = &stored
+	return &stored, nil
+}
+
+func (r *memoryLinkHealthRepository) MarkNotified(int) error {
+	return nil
+}
+
+// recordingTransport answers every request after a short pause and records
+// what a polite checker must not do: requests to a host closer together
+// than the host delay, overlapping requests to a host, and more requests in
+// flight than there are workers.
+type recordingTransport struct {
+	hostDelay time.Duration
+	failing   map[string]bool
+
+	mu          sync.Mutex
+	requested   map[string]bool
+	lastEnd     map[string]time.Time
+	busy        map[string]bool
+	inFlight    int
+	maxInFlight int
+	problems    []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+
+	t.mu.Lock()
+	t.requested[req.URL.String()] = true
+	if t.busy[host] {
+		t.problems = append(t.problems, "overlapping requests to "+host)
+	}
+	// A GET right after a failed HEAD belongs to the same check, so only
+	// HEAD requests must wait for the delay.
+	if end, ok := t.lastEnd[host]; ok && req.Method == http.MethodHead && time.Since(end) < t.hostDelay {
+		t.problems = append(t.problems, "requests to "+host+" closer than the host delay")
+	}
+	t.busy[host] = true
+	t.inFlight++
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	t.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	t.mu.Lock()
+	t.busy[host] = false
+	t.inFlight--
+	t.lastEnd[host] = time.Now()
+	t.mu.Unlock()
+
+	status := http.StatusOK
+	if t.failing[req.URL.String()] {
+		status = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestHealthCheckerChecksActiveLinks(t *testing.T) {
+	links := newMemoryLinkRepository()
+	healthRepo := &memoryLinkHealthRepository{health: map[int]*repository.LinkHealth{}}
+	now := time.Now()
+
+	var ids []int
+	for _, link := range []*repository.Link{
+		{OriginalURL: "https://a.example/1"},
+		{OriginalURL: "https://b.example/1", Variants: []repository.LinkVariant{
+			{Name: "a", URL: "https://b.example/1", Weight: 1},
+			{Name: "b", URL: "https://c.example/broken", Weight: 1},
+		}},
+		{OriginalURL: "https://disabled.example/", DisabledReason: "abuse"},
+		{OriginalURL: "https://a.example/2"},
+		{OriginalURL: "https://trashed.example/", DeletedAt: &now},
+		{OriginalURL: "https://a.example/3"},
+		{OriginalURL: "https://d.example/1"},
+	} {
+		created, _ := links.Create(link)
+		ids = append(ids, created.ID)
+	}
+
+	hostDelay := 20 * time.Millisecond
+	transport := &recordingTransport{
+		hostDelay: hostDelay,
+		failing:   map[string]bool{"https://c.example/broken": true},
+		requested: map[string]bool{},
+		lastEnd:   map[string]time.Time{},
+		busy:      map[string]bool{},
+	}
+	checker := NewHealthChecker(links, healthRepo, nil, time.Hour, time.Second, hostDelay, 2, 3)
+	checker.client = &http.Client{Transport: transport}
+	checker.pageSize = 2
+
+	checker.checkAll(context.Background())
+
+	for _, problem := range transport.problems {
+		t.Error(problem)
+	}
+	if transport.maxInFlight > 2 {
+		t.Errorf("%d requests were in flight at once, want at most 2", transport.maxInFlight)
+	}
+	for _, rawURL := range []string{"https://disabled.example/", "https://trashed.example/"} {
+		if transport.requested[rawURL] {
+			t.Errorf("%s should not be checked", rawURL)
+		}
+	}
+	if !transport.requested["https://c.example/broken"] {
+		t.Error("the variant destination was not checked")
+	}
+
+	for _, i := range []int{0, 1, 3, 5, 6} {
+		if healthRepo.health[ids[i]] == nil {
+			t.Errorf("link %d has no stored health", ids[i])
+		}
+	}
+	if health := healthRepo.health[ids[0]]; health != nil && (!health.Healthy || health.URL != "https://a.example/1") {
+		t.Errorf("healthy link stored %+v", health)
+	}
+	if health := healthRepo.health[ids[1]]; health != nil && (health.Healthy || health.URL != "https://c.example/broken") {
+		t.Errorf("link with a broken variant stored %+v, want the failing variant", health)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// reservedQueryParams are used by the short URL itself and are never passed
+// through to the destination.
+var reservedQueryParams = map[string]bool{
+	"src":     true,
+	"proceed": true,
+}
+
+// Visit describes the request that followed a short link. IP is the
+// visitor's address after trusted proxies are taken into account, and
+// Variant the A/B variant chosen for the visitor, if any. Method and Header
+// are only used to recognise bots.
+type Visit struct {
+	Query     url.Values
+	UserAgent string
+	IP        net.IP
+	Variant   string
+	Method    string
+	Header    http.Header
+}
+
+type queryParam struct {
+	name  string
+	value string
+}
+
+// ResolveDestination returns the URL the visitor is redirected to. Until the
+// link's schedule starts that is its pending URL. Otherwise it is the
+// universal link or app link for the visitor's platform, the destination of
+// the first matching targeting rule, then that of the visitor's A/B variant,
+// or the link's own URL as changed by the schedule.
+// Query parameters are then merged with the following precedence, lowest first:
+//
+//  1. parameters already present in the destination URL;
+//  2. the link's UTM fields;
+//  3. the short URL's own query string, when passthrough is enabled.
+//
+// A parameter set at a higher level replaces every value of the same name
+// from the lower levels.
+func (s *LinkService) ResolveDestination(link *repository.Link, visit Visit) string {
+	return mergeQuery(s.destination(link, visit), linkQueryParams(link, visit))
+}
+
+// DestinationThreat returns the threat type of the destination chosen for
+// the visitor, or an empty string when it has not been flagged.
+func (s *LinkService) DestinationThreat(link *repository.Link, visit Visit) string {
+	destination := s.destination(link, visit)
+	if destination == link.OriginalURL {
+		return link.ThreatType
+	}
+	return link.DestinationThreats[destination]
+}
+
+// destination returns the stored URL chosen for the visitor, before query
+// parameters are merged into it.
+func (s *LinkService) destination(link *repository.Link, visit Visit) string {
+	now := time.Now()
+	if scheduledPending(link.Schedule, now) {
+		return link.Schedule.PendingURL
+	}
+
+	if appLink := deepLinkURL(link, visit); appLink != "" {
+		return appLink
+	}
+	if rule := s.matchRule(link, visit); rule != nil {
+		return rule.URL
+	}
+	if variant := findVariant(link.Variants, visit.Variant); variant != nil {
+		return variant.URL
+	}
+	return scheduledURL(link, now)
+}
+
+// linkDestinations lists every URL the link can redirect to, starting with
+// its own.
+func linkDestinations(link *repository.Link) []string {
+	return append([]string{link.OriginalURL}, extraDestinations(link)...)
+}
+
+// extraDestinations lists the URLs other than OriginalURL the link can
+// redirect to. Their threat types are kept in DestinationThreats.
+func extraDestinations(link *repository.Link) []string {
+	var urls []string
+	for _, rule := range link.TargetingRules {
+		urls = append(urls, rule.URL)
+	}
+	for _, variant := range link.Variants {
+		urls = append(urls, variant.URL)
+	}
+	if link.Schedule != nil {
+		if link.Schedule.PendingURL != "" {
+			urls = append(urls, link.Schedule.PendingURL)
+		}
+		for _, change := range link.Schedule.Changes {
+			urls = append(urls, change.URL)
+		}
+	}
+	if link.DeepLink != nil {
+		for _, url := range []string{link.DeepLink.IOSURL, link.DeepLink.AndroidURL} {
+			if url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// matchRule returns the link's targeting rule that applies to the visitor.
+func (s *LinkService) matchRule(link *repository.Link, visit Visit) *repository.TargetingRule {
+	if len(link.TargetingRules) == 0 {
+		return nil
+	}
+
+	var location GeoLocation
+	if needsLocation(link.TargetingRules) {
+		location = s.geo.Lookup(visit.IP)
+	}
+	return matchTargetingRule(link.TargetingRules, ParseDevice(visit.UserAgent), location)
+}
+
+func linkQueryParams(link *repository.Link, visit Visit) []queryParam {
+	var params []queryParam
+
+	utm := []queryParam{
+		{"utm_source", link.UTMSource},
+		{"utm_medium", link.UTMMedium},
+		{"utm_campaign", link.UTMCampaign},
+		{"utm_term", link.UTMTerm},
+		{"utm_content", link.UTMContent},
+	}
+	for _, param := range utm {
+		if param.value != "" {
+			params = append(params, param)
+		}
+	}
+
+	if !link.QueryPassthrough {
+		return params
+	}
+
+	var names []string
+	for name := range visit.Query {
+		if !reservedQueryParams[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var merged []queryParam
+	for _, param := range params {
+		if _, ok := visit.Query[param.name]; !ok {
+			merged = append(merged, param)
+		}
+	}
+	for _, name := range names {
+		for _, value := range visit.Query[name] {
+			merged = append(merged, queryParam{name, value})
+		}
+	}
+
+	return merged
+}
+
+// mergeQuery replaces or adds params in the destination's query string. The
+// remaining parameters of the destination keep their order and encoding.
+func mergeQuery(destination string, params []queryParam) string {
+	if len(params) == 0 {
+		return destination
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	replaced := map[string]bool{}
+	for _, param := range params {
+		replaced[param.name] = true
+	}
+
+	var parts []string
+	for _, part := range strings.Split(u.RawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		name, _ := url.QueryUnescape(queryParamName(part))
+		if !replaced[name] {
+			parts = append(parts, part)
+		}
+	}
+	for _, param := range params {
+		parts = append(parts, url.QueryEscape(param.name)+"="+url.QueryEscape(param.value))
+	}
+
+	u.RawQuery = strings.Join(parts, "&")
+	return u.String()
+}
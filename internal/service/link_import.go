@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// MaxImportRows limits how many rows a single import can contain. Imports
+// run within the request, so the limit keeps them well inside the server's
+// write timeout.
+const MaxImportRows = 1000
+
+const (
+	ImportStatusCreated  = "created"
+	ImportStatusExisting = "existing"
+	ImportStatusFailed   = "failed"
+)
+
+type ImportLinkInput struct {
+	Row         int
+	URL         string
+	ShortCode   string
+	Title       string
+	Description string
+	Notes       string
+	Tags        []string
+}
+
+type ImportLinkResult struct {
+	Row           int
+	Status        string
+	Link          *repository.Link
+	CodePreserved bool
+	Err           error
+}
+
+// ImportLinks creates links from imported rows. A provided short code is
+// kept when it is free; otherwise a new one is generated and the row is
+// reported with CodePreserved set to false. All destinations are checked
+// for threats with a single scanner call.
+func (s *LinkService) ImportLinks(items []ImportLinkInput, userID int) ([]ImportLinkResult, error) {
+	if len(items) == 0 {
+		return nil, errors.New("import is empty")
+	}
+	if len(items) > MaxImportRows {
+		return nil, errors.New("import is too large")
+	}
+
+	urls := make([]string, len(items))
+	for i, item := range items {
+		urls[i] = item.URL
+	}
+	threats := s.scanThreats(urls)
+
+	results := make([]ImportLinkResult, len(items))
+	for i, item := range items {
+		results[i] = s.importLink(item, userID, threats)
+	}
+
+	return results, nil
+}
+
+func (s *LinkService) importLink(item ImportLinkInput, userID int, threats map[string]string) ImportLinkResult {
+	result := ImportLinkResult{Row: item.Row, Status: ImportStatusFailed}
+
+	url, err := s.checkDestination(item.URL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	item.URL = url
+
+	existing, err := s.linkRepo.FindByURLAndUser(item.URL, userID, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if existing != nil && (item.ShortCode == "" || item.ShortCode == existing.ShortCode) {
+		result.Status = ImportStatusExisting
+		result.Link = existing
+		result.CodePreserved = item.ShortCode != ""
+		return result
+	}
+
+	alias := ""
+	if item.ShortCode != "" && aliasPattern.MatchString(item.ShortCode) {
+		exists, err := s.linkRepo.ShortCodeExists(nil, item.ShortCode)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		if !exists {
+			alias = item.ShortCode
+		}
+	}
+
+	if existing != nil && alias == "" {
+		result.Status = ImportStatusExisting
+		result.Link = existing
+		return result
+	}
+
+	link, _, err := s.create(CreateLinkInput{
+		URL:         item.URL,
+		Alias:       alias,
+		Title:       truncateRunes(item.Title, MaxTitleLength),
+		Description: truncateRunes(item.Description, MaxDescriptionLength),
+		Notes:       truncateRunes(item.Notes, MaxNotesLength),
+		Tags:        item.Tags,
+	}, userID, threats)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Status = ImportStatusCreated
+	result.Link = link
+	result.CodePreserved = alias != ""
+	return result
+}
+
+func (s *LinkService) ExportLinks(userID int, fn func(*repository.Link) error) error {
+	return s.linkRepo.EachByUserID(userID, fn)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+const (
+	DefaultLinkPageSize = 50
+	MaxLinkPageSize     = 200
+)
+
+type LinkListParams struct {
+	Sort        string
+	Order       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// DestinationHost matches the host of the destination URL, not the
+	// custom domain the short link is served on; DomainID does that.
+	DestinationHost string
+	DomainID        *int
+	Tag             string
+	FolderID        *int
+	Cursor          string
+	Limit           int
+}
+
+type LinkList struct {
+	Links      []*repository.Link
+	Total      int
+	NextCursor string
+}
+
+// linkCursor is the decoded form of the opaque cursor handed to clients. It
+// remembers the ordering it was issued for, so it cannot be replayed against
+// a different sort.
+type linkCursor struct {
+	Sort  string                `json:"s"`
+	Order string                `json:"o"`
+	After repository.LinkCursor `json:"a"`
+}
+
+func (s *LinkService) ListLinks(userID int, params LinkListParams) (*LinkList, error) {
+	query := repository.LinkListQuery{
+		UserID:          userID,
+		Sort:            params.Sort,
+		CreatedFrom:     params.CreatedFrom,
+		CreatedTo:       params.CreatedTo,
+		DestinationHost: params.DestinationHost,
+		DomainID:        params.DomainID,
+		Tag:             params.Tag,
+		FolderID:        params.FolderID,
+		Limit:           params.Limit,
+	}
+
+	if query.Sort == "" {
+		query.Sort = repository.LinkSortCreatedAt
+	}
+	switch query.Sort {
+	case repository.LinkSortCreatedAt, repository.LinkSortClicks, repository.LinkSortName:
+	default:
+		return nil, ValidationError("invalid sort")
+	}
+
+	order := params.Order
+	if order == "" {
+		order = "desc"
+		if query.Sort == repository.LinkSortName {
+			order = "asc"
+		}
+	}
+	switch order {
+	case "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return nil, ValidationError("invalid order")
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultLinkPageSize
+	}
+	if query.Limit > MaxLinkPageSize {
+		query.Limit = MaxLinkPageSize
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeLinkCursor(params.Cursor)
+		if err != nil || cursor.Sort != query.Sort || cursor.Order != order {
+			return nil, ValidationError("invalid cursor")
+		}
+		query.After = &cursor.After
+	}
+
+	page, err := s.linkRepo.FindPage(query)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &LinkList{Links: page.Links, Total: page.Total}
+	if list.Links == nil {
+		list.Links = []*repository.Link{}
+	}
+	if page.Next != nil {
+		list.NextCursor, err = encodeLinkCursor(linkCursor{Sort: query.Sort, Order: order, After: *page.Next})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func encodeLinkCursor(cursor linkCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeLinkCursor(value string) (*linkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor linkCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"html"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// highlightContext is how many characters are kept on each side of the
+	// first match when a highlighted fragment is cut out of a long field.
+	highlightContext = 40
+)
+
+type LinkSearchHit struct {
+	Link       *repository.Link
+	Rank       float64
+	Highlights map[string]string
+}
+
+func (s *LinkService) SearchLinks(userID int, query string, limit int) ([]*LinkSearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ValidationError("search query is required")
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	results, err := s.linkRepo.Search(userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	hits := make([]*LinkSearchHit, len(results))
+	for i, result := range results {
+		hit := &LinkSearchHit{
+			Link:       result.Link,
+			Rank:       result.Rank,
+			Highlights: map[string]string{},
+		}
+		fields := map[string]string{
+			"original_url": result.Link.OriginalURL,
+			"short_code":   result.Link.ShortCode,
+			"title":        result.Link.Title,
+			"description":  result.Link.Description,
+			"notes":        result.Link.Notes,
+		}
+		for name, value := range fields {
+			if fragment, ok := highlight(value, terms); ok {
+				hit.Highlights[name] = fragment
+			}
+		}
+		hits[i] = hit
+	}
+
+	return hits, nil
+}
+
+// searchTerms splits a web-search style query into lowercase terms, dropping
+// quotes, exclusions and the OR operator.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if word == "or" || strings.HasPrefix(word, "-") {
+			continue
+		}
+		word = strings.Trim(word, `"`)
+		if word != "" {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// highlight wraps every occurrence of the terms in <mark> tags. Text outside
+// the marks is HTML-escaped, so the fragment is safe to render as-is. Long
+// values are trimmed to a window around the first match.
+func highlight(value string, terms []string) (string, bool) {
+	lower := strings.ToLower(value)
+	if len(lower) != len(value) {
+		// Lowercasing changed byte offsets; fall back to exact-case matching.
+		lower = value
+	}
+
+	marked := make([]bool, len(value))
+	first := -1
+	for _, term := range terms {
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], term)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			for j := start; j < start+len(term); j++ {
+				marked[j] = true
+			}
+			if first < 0 || start < first {
+				first = start
+			}
+			offset = start + len(term)
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	from, to := 0, len(value)
+	if first > highlightContext {
+		from = first - highlightContext
+	}
+	if to-first > 2*highlightContext {
+		to = first + 2*highlightContext
+	}
+	for from > 0 && !isRuneStart(value[from]) {
+		from--
+	}
+	for to < len(value) && !isRuneStart(value[to]) {
+		to++
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	for i := from; i < to; {
+		j := i
+		for j < to && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString("<mark>" + html.EscapeString(value[i:j]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(value[i:j]))
+		}
+		i = j
+	}
+	if to < len(value) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -1,57 +1,286 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
+	"log"
+	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"github.com/RamanDudoits/shortLink-go/internal/repository"
 )
 
 type LinkServiceInterface interface {
-	UpdateLink(id, userID int, updates map[string]interface{}) (*repository.Link, error)
+	UpdateLink(id, userID int, update repository.LinkUpdate) (*repository.Link, error)
 	GetLink(id, userID int) (*repository.Link, error)
-	Create(originalURL string, userID int) (*repository.Link, error)
-	GetUserLinks(userID int) ([]*repository.Link, error)
+	Create(input CreateLinkInput, userID int) (*repository.Link, error)
+	CreateBatch(items []CreateLinkInput, userID int) ([]BatchLinkResult, error)
+	ImportLinks(items []ImportLinkInput, userID int) ([]ImportLinkResult, error)
+	ExportLinks(userID int, fn func(*repository.Link) error) error
+	ListLinks(userID int, params LinkListParams) (*LinkList, error)
+	SearchLinks(userID int, query string, limit int) ([]*LinkSearchHit, error)
 	DeleteLink(id, userID int) error
+	GetLinkVersions(id, userID int) ([]*repository.LinkVersion, error)
+	RevertLink(id, userID, version int) (*repository.Link, error)
+	GetDeletedLinks(userID int) ([]*repository.Link, error)
+	RestoreLink(id, userID int) error
+	SetLinkTags(id, userID int, tags []string) (*repository.Link, error)
+	MoveLink(id, userID int, folderID *int) (*repository.Link, error)
+	SetTargetingRules(id, userID int, rules []repository.TargetingRule) (*repository.Link, error)
+	GetGeoStats(id, userID int, from, to *time.Time, includeBots bool) (*repository.GeoStats, error)
+	SetVariants(id, userID int, variants []repository.LinkVariant) (*repository.Link, error)
+	GetVariantStats(id, userID int, from, to *time.Time, includeBots bool) ([]*repository.VariantCount, error)
+	GetVisitorStats(id, userID int, from, to *time.Time) (*repository.VisitorStats, error)
+	SetSchedule(id, userID int, schedule *repository.LinkSchedule) (*repository.Link, error)
+	SetDeepLink(id, userID int, deepLink *repository.DeepLink) (*repository.Link, error)
 }
 
 type LinkServiceRedirectInterface interface {
-	GetByShortCode(shortLink string) (*repository.Link, error)
-	IncrementClickCount(id int, clickCount int) error
+	GetByShortCode(host, shortLink string) (*repository.Link, error)
+	CheckSchedule(link *repository.Link) error
+	RecordClick(link *repository.Link, source string, visit Visit) error
+	ChooseVariant(link *repository.Link, visit Visit) string
+	ResolveDestination(link *repository.Link, visit Visit) string
+	DestinationThreat(link *repository.Link, visit Visit) string
+	AppURL(link *repository.Link, visit Visit) string
+}
+
+// MaxBatchSize limits how many links can be created by a single batch request.
+const MaxBatchSize = 500
+
+// Length limits for link metadata, in characters.
+const (
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 1000
+	MaxNotesLength       = 5000
+	MaxUTMLength         = 255
+)
+
+// threatScanTimeout bounds the threat scan done while creating or updating
+// a link, so a slow provider does not block the request for long.
+const threatScanTimeout = 3 * time.Second
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+type CreateLinkInput struct {
+	URL         string
+	Alias       string
+	Title       string
+	Description string
+	Notes       string
+	Tags        []string
+	FolderID    *int
+	DomainID    *int
+
+	Interstitial bool
+
+	UTMSource        string
+	UTMMedium        string
+	UTMCampaign      string
+	UTMTerm          string
+	UTMContent       string
+	QueryPassthrough bool
+}
+
+// hasSettings reports whether the input sets anything besides the URL, the
+// alias and the domain. Such input always creates a new link, so the settings
+// are never silently dropped by reusing an existing one.
+func (in CreateLinkInput) hasSettings() bool {
+	return in.Title != "" || in.Description != "" || in.Notes != "" || len(in.Tags) > 0 ||
+		in.FolderID != nil || in.Interstitial || in.UTMSource != "" || in.UTMMedium != "" ||
+		in.UTMCampaign != "" || in.UTMTerm != "" || in.UTMContent != "" || in.QueryPassthrough
+}
+
+type BatchLinkResult struct {
+	Link *repository.Link
+	// Existing is set when the user already had a link to the URL and it
+	// was returned instead of creating a new one.
+	Existing bool
+	Err      error
 }
 
 type LinkService struct {
-	linkRepo repository.LinkRepository
+	linkRepo   repository.LinkRepository
+	tagRepo    repository.TagRepository
+	folderRepo repository.FolderRepository
+	clickRepo  repository.ClickRepository
+	domainRepo repository.DomainRepository
+	urlPolicy  *URLPolicy
+	domains    *DomainFilter
+	threats    ThreatScanner
+	titles     *TitleResolver
+	geo        *GeoIPDatabase
+	bots       *BotFilter
+	visitors   *VisitorCounter
 }
 
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
-	return &LinkService{linkRepo: linkRepo}
+// NewLinkService creates the link service. threats may be nil to skip threat
+// scanning, and titles may be nil, in which case links created without a
+// title keep an empty one. geo may be nil, leaving visitors without a
+// location, and bots may be nil to only treat HEAD and prefetch requests as
+// bots.
+func NewLinkService(
+	linkRepo repository.LinkRepository,
+	tagRepo repository.TagRepository,
+	folderRepo repository.FolderRepository,
+	clickRepo repository.ClickRepository,
+	domainRepo repository.DomainRepository,
+	urlPolicy *URLPolicy,
+	domains *DomainFilter,
+	threats ThreatScanner,
+	titles *TitleResolver,
+	geo *GeoIPDatabase,
+	bots *BotFilter,
+	visitors *VisitorCounter,
+) *LinkService {
+	return &LinkService{
+		linkRepo:   linkRepo,
+		tagRepo:    tagRepo,
+		folderRepo: folderRepo,
+		clickRepo:  clickRepo,
+		domainRepo: domainRepo,
+		urlPolicy:  urlPolicy,
+		domains:    domains,
+		threats:    threats,
+		titles:     titles,
+		geo:        geo,
+		bots:       bots,
+		visitors:   visitors,
+	}
 }
 
-func (s *LinkService) Create(originalURL string, userID int) (*repository.Link, error) {
-	if existing, _ := s.linkRepo.FindByURLAndUser(originalURL, userID); existing != nil {
-		return existing, nil
+// Create shortens the URL for the user. The URL is normalized first, and an
+// existing link to the same URL is returned instead of creating a duplicate,
+// unless a different alias or any other setting was requested. When no title
+// is given, it is fetched from the destination page in the background.
+func (s *LinkService) Create(input CreateLinkInput, userID int) (*repository.Link, error) {
+	link, _, err := s.create(input, userID, nil)
+	return link, err
+}
+
+// create creates the link, or returns the user's existing link to the same
+// URL when the input sets nothing else; existing reports which one happened.
+// threats holds the results of an earlier scanThreats call covering the URL;
+// when it is nil the URL is scanned here.
+func (s *LinkService) create(input CreateLinkInput, userID int, threats map[string]string) (*repository.Link, bool, error) {
+	url, err := s.checkDestination(input.URL)
+	if err != nil {
+		return nil, false, err
 	}
+	input.URL = url
 
-	shortCode, err := generateShortCode(originalURL)
+	if err := validateLinkMetadata(input.Title, input.Description, input.Notes); err != nil {
+		return nil, false, err
+	}
+	if err := validateUTM(
+		input.UTMSource, input.UTMMedium, input.UTMCampaign, input.UTMTerm, input.UTMContent,
+	); err != nil {
+		return nil, false, err
+	}
+
+	tags, err := normalizeTagNames(input.Tags)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	if input.FolderID != nil {
+		if err := s.ensureFolderOwner(*input.FolderID, userID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if input.DomainID != nil {
+		if err := s.ensureDomainUsable(*input.DomainID, userID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if !input.hasSettings() {
+		existing, err := s.linkRepo.FindByURLAndUser(input.URL, userID, input.DomainID)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil && (input.Alias == "" || input.Alias == existing.ShortCode) {
+			return existing, true, nil
+		}
+	}
+
+	shortCode, err := s.resolveShortCode(input.URL, input.Alias, input.DomainID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var threatType string
+	if threats != nil {
+		threatType = threats[input.URL]
+	} else {
+		threatType = s.scanThreat(input.URL)
 	}
-	
+
 	link := &repository.Link{
-		OriginalURL: originalURL,
-		ShortCode:   shortCode,
-		UserID:      userID,
-		ClickCount:  0,
-		CreatedAt:   time.Now(),
+		OriginalURL:      input.URL,
+		ShortCode:        shortCode,
+		Title:            input.Title,
+		Description:      input.Description,
+		Notes:            input.Notes,
+		Tags:             tags,
+		FolderID:         input.FolderID,
+		DomainID:         input.DomainID,
+		Interstitial:     input.Interstitial,
+		UTMSource:        input.UTMSource,
+		UTMMedium:        input.UTMMedium,
+		UTMCampaign:      input.UTMCampaign,
+		UTMTerm:          input.UTMTerm,
+		UTMContent:       input.UTMContent,
+		QueryPassthrough: input.QueryPassthrough,
+		TargetingRules:   []repository.TargetingRule{},
+		Variants:         []repository.LinkVariant{},
+		ThreatType:       threatType,
+		UserID:           userID,
+		ClickCount:       0,
+		ClickSources:     map[string]int{},
+		CreatedAt:        time.Now(),
 	}
-	
-	return s.linkRepo.Create(link)
+
+	// The link and its tags are stored in one transaction.
+	link, err = s.linkRepo.Create(link)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if link.Title == "" && s.titles != nil {
+		s.titles.Enqueue(link.ID, link.OriginalURL)
+	}
+
+	return link, false, nil
 }
 
-func (s *LinkService) GetUserLinks(userID int) ([]*repository.Link, error) {
-	return s.linkRepo.FindByUserID(userID)
+// CreateBatch creates every item independently, so one invalid URL or taken
+// alias does not prevent the rest of the batch from being created. All
+// destinations are checked for threats with a single scanner call.
+func (s *LinkService) CreateBatch(items []CreateLinkInput, userID int) ([]BatchLinkResult, error) {
+	if len(items) == 0 {
+		return nil, errors.New("batch is empty")
+	}
+	if len(items) > MaxBatchSize {
+		return nil, errors.New("batch is too large")
+	}
+
+	urls := make([]string, len(items))
+	for i, item := range items {
+		urls[i] = item.URL
+	}
+	threats := s.scanThreats(urls)
+
+	results := make([]BatchLinkResult, len(items))
+	for i, item := range items {
+		link, existing, err := s.create(item, userID, threats)
+		results[i] = BatchLinkResult{Link: link, Existing: existing, Err: err}
+	}
+
+	return results, nil
 }
 
 func (s *LinkService) GetLink(id, userID int) (*repository.Link, error) {
@@ -61,54 +290,484 @@ func (s *LinkService) GetLink(id, userID int) (*repository.Link, error) {
 	}
 
 	if link.UserID != userID {
-		return nil, errors.New("access denied")
+		return nil, ErrAccessDenied
 	}
 
 	return link, nil
 }
 
-func (s *LinkService) UpdateLink(id, userID int, updates map[string]interface{}) (*repository.Link, error) {
+// UpdateLink applies a partial update. An update without any fields returns
+// the link unchanged and does not create a new version.
+func (s *LinkService) UpdateLink(id, userID int, update repository.LinkUpdate) (*repository.Link, error) {
+	link, err := s.GetLink(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update == (repository.LinkUpdate{}) {
+		return link, nil
+	}
+
+	if update.OriginalURL != nil {
+		url, err := s.checkDestination(*update.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		update.OriginalURL = &url
+	}
+
+	if update.ShortCode != nil {
+		if !aliasPattern.MatchString(*update.ShortCode) {
+			return nil, ValidationError("invalid alias")
+		}
+		if err := s.ensureShortCodeAvailable(link, *update.ShortCode); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateLinkMetadata(
+		stringValue(update.Title), stringValue(update.Description), stringValue(update.Notes),
+	); err != nil {
+		return nil, err
+	}
+	if err := validateUTM(
+		stringValue(update.UTMSource), stringValue(update.UTMMedium), stringValue(update.UTMCampaign),
+		stringValue(update.UTMTerm), stringValue(update.UTMContent),
+	); err != nil {
+		return nil, err
+	}
+
+	if update.Routing != nil {
+		routing, err := s.normalizeRouting(*update.Routing)
+		if err != nil {
+			return nil, err
+		}
+		update.Routing = routing
+	}
+
+	updated, err := s.linkRepo.Update(id, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.OriginalURL != nil && *update.OriginalURL != link.OriginalURL {
+		updated.ThreatType = s.scanThreat(updated.OriginalURL)
+		if err := s.linkRepo.SetThreat(id, updated.ThreatType); err != nil {
+			return nil, err
+		}
+	}
+	if update.Routing != nil {
+		if err := s.scanDestinations(updated); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *LinkService) normalizeRouting(routing repository.LinkRouting) (*repository.LinkRouting, error) {
+	var err error
+	if routing.TargetingRules, err = s.normalizeTargetingRules(routing.TargetingRules); err != nil {
+		return nil, err
+	}
+	if routing.Variants, err = s.normalizeVariants(routing.Variants); err != nil {
+		return nil, err
+	}
+	if routing.Schedule != nil {
+		if routing.Schedule, err = s.normalizeSchedule(*routing.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	if routing.DeepLink, err = s.normalizeDeepLink(routing.DeepLink); err != nil {
+		return nil, err
+	}
+	return &routing, nil
+}
+
+func (s *LinkService) GetLinkVersions(id, userID int) ([]*repository.LinkVersion, error) {
 	if _, err := s.GetLink(id, userID); err != nil {
 		return nil, err
 	}
 
-	return s.linkRepo.Update(id, updates)
+	return s.linkRepo.FindVersions(id)
+}
+
+// RevertLink restores the destination and settings stored in the given
+// version. Versions recorded before settings were versioned only restore the
+// URL, short code, title and description. It goes through UpdateLink, so old
+// URLs are checked against the current URL policy and domain rules and
+// scanned for threats again. The revert itself is recorded as a new version,
+// so history is never rewritten.
+func (s *LinkService) RevertLink(id, userID, version int) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	v, err := s.linkRepo.FindVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	update := repository.LinkUpdate{
+		OriginalURL: &v.OriginalURL,
+		ShortCode:   &v.ShortCode,
+		Title:       &v.Title,
+		Description: &v.Description,
+	}
+	if settings := v.Settings; settings != nil {
+		update.Interstitial = &settings.Interstitial
+		update.UTMSource = &settings.UTMSource
+		update.UTMMedium = &settings.UTMMedium
+		update.UTMCampaign = &settings.UTMCampaign
+		update.UTMTerm = &settings.UTMTerm
+		update.UTMContent = &settings.UTMContent
+		update.QueryPassthrough = &settings.QueryPassthrough
+		update.Routing = &settings.LinkRouting
+	}
+
+	return s.UpdateLink(id, userID, update)
 }
 
 func (s *LinkService) DeleteLink(id, userID int) error {
 	return s.linkRepo.Delete(id, userID)
 }
 
-func (s *LinkService) IncrementClickCount(id int, clickCount int) error {
-_, err := s.linkRepo.Update(id, map[string]interface{}{
-        "clicks": clickCount + 1,
-    })
-    return err
+func (s *LinkService) SetLinkTags(id, userID int, tags []string) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTagNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.SetLinkTags(id, userID, tags); err != nil {
+		return nil, err
+	}
+
+	return s.linkRepo.FindByID(id)
+}
+
+// MoveLink places the link into the folder, or back to the top level when
+// folderID is nil.
+func (s *LinkService) MoveLink(id, userID int, folderID *int) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	if folderID != nil {
+		if err := s.ensureFolderOwner(*folderID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.folderRepo.MoveLink(id, folderID); err != nil {
+		return nil, err
+	}
+
+	return s.linkRepo.FindByID(id)
+}
+
+func (s *LinkService) ensureFolderOwner(folderID, userID int) error {
+	folder, err := s.folderRepo.FindByID(folderID)
+	if err != nil {
+		return err
+	}
+	if folder.UserID != userID {
+		return repository.ErrFolderNotFound
+	}
+	return nil
+}
+
+func (s *LinkService) GetDeletedLinks(userID int) ([]*repository.Link, error) {
+	return s.linkRepo.FindDeletedByUserID(userID)
+}
+
+func (s *LinkService) RestoreLink(id, userID int) error {
+	return s.linkRepo.Restore(id, userID)
+}
+
+// ensureShortCodeAvailable fails when the short code is already used by
+// another link on the same domain. Codes of links in the trash stay reserved
+// until purged.
+func (s *LinkService) ensureShortCodeAvailable(link *repository.Link, shortCode string) error {
+	if shortCode == link.ShortCode {
+		return nil
+	}
+
+	exists, err := s.linkRepo.ShortCodeExists(link.DomainID, shortCode)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return repository.ErrShortCodeTaken
+	}
+
+	return nil
+}
+
+// checkDestination normalizes the URL and checks it against the domain
+// rules. It returns the URL that should be stored.
+func (s *LinkService) checkDestination(rawURL string) (string, error) {
+	url, err := s.urlPolicy.Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.domains.Check(url); err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// scanThreat returns the threat type reported for the URL. Scanner failures
+// are logged and the URL is treated as clean; the periodic rescan will
+// catch it later.
+func (s *LinkService) scanThreat(url string) string {
+	if s.threats == nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), threatScanTimeout)
+	defer cancel()
+
+	threats, err := s.threats.Scan(ctx, []string{url})
+	if err != nil {
+		log.Printf("Failed to scan %s for threats: %v", url, err)
+		return ""
+	}
+
+	return threats[url]
+}
+
+// scanThreats checks many URLs with a single scanner call. The result is
+// keyed by the normalized URLs, as stored on links; URLs that fail
+// normalization are skipped. Scanner failures are logged and every URL is
+// treated as clean.
+func (s *LinkService) scanThreats(rawURLs []string) map[string]string {
+	threats := map[string]string{}
+	if s.threats == nil {
+		return threats
+	}
+
+	seen := map[string]bool{}
+	var urls []string
+	for _, rawURL := range rawURLs {
+		url, err := s.urlPolicy.Normalize(rawURL)
+		if err != nil || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	if len(urls) == 0 {
+		return threats
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), threatScanTimeout)
+	defer cancel()
+
+	found, err := s.threats.Scan(ctx, urls)
+	if err != nil {
+		log.Printf("Failed to scan %d urls for threats: %v", len(urls), err)
+		return threats
+	}
+	for url, threat := range found {
+		threats[url] = threat
+	}
+
+	return threats
+}
+
+// scanDestinations checks the link's other destinations for threats and
+// records the flagged ones on the link.
+func (s *LinkService) scanDestinations(link *repository.Link) error {
+	if s.threats == nil {
+		return nil
+	}
+
+	threats := s.scanThreats(extraDestinations(link))
+	if err := s.linkRepo.SetDestinationThreats(link.ID, threats); err != nil {
+		return err
+	}
+	link.DestinationThreats = threats
+
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func validateLinkMetadata(title, description, notes string) error {
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return ValidationError("title is too long")
+	}
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return ValidationError("description is too long")
+	}
+	if utf8.RuneCountInString(notes) > MaxNotesLength {
+		return ValidationError("notes are too long")
+	}
+	return nil
+}
+
+func validateUTM(values ...string) error {
+	for _, value := range values {
+		if utf8.RuneCountInString(value) > MaxUTMLength {
+			return ValidationError("utm parameter is too long")
+		}
+	}
+	return nil
+}
+
+// ensureDomainUsable fails unless the custom domain belongs to the user and
+// has been verified.
+func (s *LinkService) ensureDomainUsable(domainID, userID int) error {
+	domain, err := s.domainRepo.FindByID(domainID)
+	if err != nil {
+		return err
+	}
+	if domain.UserID != userID {
+		return repository.ErrDomainNotFound
+	}
+	if domain.VerifiedAt == nil {
+		return ErrDomainNotVerified
+	}
+	return nil
+}
+
+func (s *LinkService) resolveShortCode(url, alias string, domainID *int) (string, error) {
+	if alias == "" {
+		return s.generateUniqueShortCode(url, domainID)
+	}
+
+	if !aliasPattern.MatchString(alias) {
+		return "", ValidationError("invalid alias")
+	}
+
+	exists, err := s.linkRepo.ShortCodeExists(domainID, alias)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", repository.ErrShortCodeTaken
+	}
+
+	return alias, nil
+}
+
+func (s *LinkService) generateUniqueShortCode(url string, domainID *int) (string, error) {
+	const maxAttempts = 10
+
+	for i := 0; i < maxAttempts; i++ {
+		shortCode, err := generateShortCode(url)
+		if err != nil {
+			return "", err
+		}
+
+		exists, err := s.linkRepo.ShortCodeExists(domainID, shortCode)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return shortCode, nil
+		}
+	}
+
+	return "", errors.New("failed to generate unique short code")
+}
+
+// RecordClick counts a visit of the link together with the visitor's
+// location and A/B variant. Visits by people also count towards the link's
+// unique visitors.
+func (s *LinkService) RecordClick(link *repository.Link, source string, visit Visit) error {
+	location := s.geo.Lookup(visit.IP)
+	bot := s.bots.IsBot(visit)
+	err := s.clickRepo.Record(&repository.Click{
+		LinkID:  link.ID,
+		Source:  source,
+		Country: location.Country,
+		Region:  location.Region,
+		Variant: visit.Variant,
+		Bot:     bot,
+	})
+	if err != nil || bot {
+		return err
+	}
+
+	return s.visitors.Record(link.ID, visit)
+}
+
+// GetGeoStats splits the link's clicks between the visitors' countries and
+// regions. Clicks by bots are only counted when includeBots is set.
+func (s *LinkService) GetGeoStats(id, userID int, from, to *time.Time, includeBots bool) (*repository.GeoStats, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.clickRepo.CountByGeo(repository.ClickStatsQuery{
+		LinkID: id, From: from, To: to, IncludeBots: includeBots,
+	})
+}
+
+// GetVisitorStats estimates the link's unique human visitors per day and for
+// the whole period.
+func (s *LinkService) GetVisitorStats(id, userID int, from, to *time.Time) (*repository.VisitorStats, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.visitors.Stats(id, from, to)
 }
 
 func generateShortCode(url string) (string, error) {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	length := 5
-    result := make([]byte, length)
+	result := make([]byte, length)
 
-    randomBytes := make([]byte, length)
-    if _, err := rand.Read(randomBytes); err != nil {
-        return "", err
-    }
+	randomBytes := make([]byte, length)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
 
-    for i := 0; i < length; i++ {
-        result[i] = charset[int(randomBytes[i])%len(charset)]
-    }
+	for i := 0; i < length; i++ {
+		result[i] = charset[int(randomBytes[i])%len(charset)]
+	}
 
-    return string(result), nil
+	return string(result), nil
 }
 
-func (s *LinkService) GetByShortCode(shortLink string) (*repository.Link, error) {
-	link, err := s.linkRepo.Find(map[string]interface{}{
-		"short_link": shortLink,
-	})
+// GetByShortCode finds the link a request for the short code on host refers
+// to. Verified custom domains have their own short codes; every other host
+// serves the links without a domain.
+func (s *LinkService) GetByShortCode(host, shortLink string) (*repository.Link, error) {
+	var domainID *int
+	domain, err := s.domainRepo.FindVerifiedByHost(requestHost(host))
+	if err != nil {
+		return nil, err
+	}
+	if domain != nil {
+		domainID = &domain.ID
+	}
+
+	link, err := s.linkRepo.FindByShortCode(domainID, shortLink)
 	if err != nil {
 		return nil, err
 	}
+	if link == nil {
+		return nil, repository.ErrLinkNotFound
+	}
+	if link.DisabledReason == repository.LinkDisabledBlockedDomain {
+		return nil, ErrLinkBlocked
+	}
+	if link.DisabledReason != "" {
+		return nil, ErrLinkDisabled
+	}
 	return link, nil
-}
\ No newline at end of file
+}
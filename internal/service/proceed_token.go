@@ -0,0 +1,59 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultProceedTokenTTL is used when the tokens are created without a TTL.
+const DefaultProceedTokenTTL = 10 * time.Minute
+
+// ProceedTokens signs the links on warning pages that let a visitor continue
+// to a flagged destination. A token is bound to the link and the destination
+// shown on the page and expires after the TTL, so it cannot be shared or
+// reused to skip the warning for another destination.
+type ProceedTokens struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewProceedTokens(secret string, ttl time.Duration) *ProceedTokens {
+	if ttl <= 0 {
+		ttl = DefaultProceedTokenTTL
+	}
+
+	return &ProceedTokens{secret: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+// Mint returns a token for continuing from the link to the destination. It
+// has the form "<expiry>.<signature>", with the expiry in Unix seconds.
+func (t *ProceedTokens) Mint(linkID int, destination string) string {
+	expires := strconv.FormatInt(t.now().Add(t.ttl).Unix(), 10)
+	return expires + "." + t.sign(linkID, destination, expires)
+}
+
+// Verify reports whether the token was minted for the link and destination
+// and has not expired.
+func (t *ProceedTokens) Verify(token string, linkID int, destination string) bool {
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || t.now().Unix() > unix {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(t.sign(linkID, destination, expires)))
+}
+
+func (t *ProceedTokens) sign(linkID int, destination, expires string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte("proceed\n" + strconv.Itoa(linkID) + "\n" + expires + "\n" + destination))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProceedTokens(t *testing.T) {
+	now := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	tokens := NewProceedTokens("secret", time.Minute)
+	tokens.now = func() time.Time { return now }
+
+	token := tokens.Mint(7, "https://phish.example/")
+	if !tokens.Verify(token, 7, "https://phish.example/") {
+		t.Fatal("a fresh token should verify")
+	}
+	_, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name        string
+		token       string
+		linkID      int
+		destination string
+	}{
+		{name: "other link", token: token, linkID: 8, destination: "https://phish.example/"},
+		{name: "other destination", token: token, linkID: 7, destination: "https://other.example/"},
+		{name: "legacy value", token: "1", linkID: 7, destination: "https://phish.example/"},
+		{name: "empty", token: "", linkID: 7, destination: "https://phish.example/"},
+		{name: "tampered expiry", token: "9999999999." + signature, linkID: 7, destination: "https://phish.example/"},
+		{name: "other secret", token: NewProceedTokens("other", time.Minute).Mint(7, "https://phish.example/"), linkID: 7, destination: "https://phish.example/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tokens.Verify(tt.token, tt.linkID, tt.destination) {
+				t.Errorf("Verify(%q) = true, want false", tt.token)
+			}
+		})
+	}
+
+	now = now.Add(2 * time.Minute)
+	if tokens.Verify(token, 7, "https://phish.example/") {
+		t.Error("an expired token should not verify")
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// Limits for QR code options.
+const (
+	DefaultQRSize   = 256
+	MinQRSize       = 64
+	MaxQRSize       = 2048
+	DefaultQRMargin = 4
+	MaxQRMargin     = 16
+)
+
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// QROptions describes how a QR code is rendered. Zero values are replaced
+// with defaults by Normalize.
+type QROptions struct {
+	Format     string
+	Size       int
+	Level      string
+	Foreground string
+	Background string
+	Margin     *int
+}
+
+// Normalize fills in defaults and validates the options.
+func (o QROptions) Normalize() (QROptions, error) {
+	if o.Format == "" {
+		o.Format = QRFormatPNG
+	}
+	if o.Format != QRFormatPNG && o.Format != QRFormatSVG {
+		return o, ValidationError("invalid qr format")
+	}
+
+	if o.Size == 0 {
+		o.Size = DefaultQRSize
+	}
+	if o.Size < MinQRSize || o.Size > MaxQRSize {
+		return o, ValidationError("invalid qr size")
+	}
+
+	o.Level = strings.ToUpper(o.Level)
+	if o.Level == "" {
+		o.Level = "M"
+	}
+	if _, ok := qrLevels[o.Level]; !ok {
+		return o, ValidationError("invalid qr error correction level")
+	}
+
+	var err error
+	if o.Foreground, err = normalizeHexColor(o.Foreground, "000000"); err != nil {
+		return o, err
+	}
+	if o.Background, err = normalizeHexColor(o.Background, "ffffff"); err != nil {
+		return o, err
+	}
+
+	if o.Margin == nil {
+		margin := DefaultQRMargin
+		o.Margin = &margin
+	}
+	if *o.Margin < 0 || *o.Margin > MaxQRMargin {
+		return o, ValidationError("invalid qr margin")
+	}
+
+	return o, nil
+}
+
+func (o QROptions) key(content string) string {
+	return fmt.Sprintf("%s|%s|%d|%s|%s|%s|%d",
+		content, o.Format, o.Size, o.Level, o.Foreground, o.Background, *o.Margin)
+}
+
+// QRCode is a rendered QR code image.
+type QRCode struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+}
+
+// QRService renders QR codes and keeps the most recently used ones in
+// memory, since the same code tends to be requested over and over.
+type QRService struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type qrCacheEntry struct {
+	key  string
+	code *QRCode
+}
+
+func NewQRService(cacheSize int) *QRService {
+	return &QRService{
+		capacity: cacheSize,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// Generate renders content as a QR code with the given options.
+func (s *QRService) Generate(content string, opts QROptions) (*QRCode, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	key := opts.key(content)
+	if code := s.cached(key); code != nil {
+		return code, nil
+	}
+
+	qr, err := qrcode.New(content, qrLevels[opts.Level])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	qr.DisableBorder = true
+	modules := qr.Bitmap()
+
+	code := &QRCode{}
+	switch opts.Format {
+	case QRFormatSVG:
+		code.Data = renderQRSVG(modules, opts)
+		code.ContentType = "image/svg+xml"
+	default:
+		code.Data, err = renderQRPNG(modules, opts)
+		if err != nil {
+			return nil, err
+		}
+		code.ContentType = "image/png"
+	}
+
+	sum := sha1.Sum([]byte(key))
+	code.ETag = `"` + hex.EncodeToString(sum[:]) + `"`
+
+	s.store(key, code)
+	return code, nil
+}
+
+func (s *QRService) cached(key string) *QRCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.order.MoveToFront(element)
+		return element.Value.(*qrCacheEntry).code
+	}
+	return nil
+}
+
+func (s *QRService) store(key string, code *QRCode) {
+	if s.capacity <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; ok {
+		return
+	}
+	s.entries[key] = s.order.PushFront(&qrCacheEntry{key: key, code: code})
+
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*qrCacheEntry).key)
+	}
+}
+
+// renderQRPNG draws every module as a square of whole pixels, so the image
+// is the largest multiple of the module count that fits into the size.
+func renderQRPNG(modules [][]bool, opts QROptions) ([]byte, error) {
+	total := len(modules) + 2**opts.Margin
+	scale := opts.Size / total
+	if scale < 1 {
+		scale = 1
+	}
+
+	palette := color.Palette{parseHexColor(opts.Background), parseHexColor(opts.Foreground)}
+	img := image.NewPaletted(image.Rect(0, 0, total*scale, total*scale), palette)
+
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			x0 := (x + *opts.Margin) * scale
+			y0 := (y + *opts.Margin) * scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(x0+dx, y0+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderQRSVG(modules [][]bool, opts QROptions) []byte {
+	total := len(modules) + 2**opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#%s"/>`, total, total, opts.Background)
+
+	fmt.Fprintf(&buf, `<path fill="#%s" d="`, opts.Foreground)
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+*opts.Margin, y+*opts.Margin)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
+
+func normalizeHexColor(value, fallback string) (string, error) {
+	value = strings.ToLower(strings.TrimPrefix(value, "#"))
+	if value == "" {
+		return fallback, nil
+	}
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) != 6 {
+		return "", ValidationError("invalid qr color")
+	}
+	if _, err := strconv.ParseUint(value, 16, 32); err != nil {
+		return "", ValidationError("invalid qr color")
+	}
+	return value, nil
+}
+
+// parseHexColor parses a color already checked by normalizeHexColor.
+func parseHexColor(value string) color.Color {
+	rgb, _ := strconv.ParseUint(value, 16, 32)
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// MaxScheduledChanges limits how many destination changes a schedule can have.
+const MaxScheduledChanges = 50
+
+// scheduleLayout is the wall-clock format of schedule times. They are read in
+// the schedule's timezone whenever the link is visited, so "09:00" stays
+// 09:00 local time across daylight saving changes.
+const scheduleLayout = "2006-01-02T15:04"
+
+var scheduleInputLayouts = []string{scheduleLayout, "2006-01-02T15:04:05", time.DateOnly}
+
+// SetSchedule replaces the link's schedule. A nil schedule makes the link
+// always active with its own URL. The pending URL and scheduled URLs go
+// through the same URL, domain and threat checks as the link itself.
+func (s *LinkService) SetSchedule(id, userID int, schedule *repository.LinkSchedule) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	if schedule != nil {
+		normalized, err := s.normalizeSchedule(*schedule)
+		if err != nil {
+			return nil, err
+		}
+		schedule = normalized
+	}
+
+	if err := s.linkRepo.SetSchedule(id, schedule); err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scanDestinations(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (s *LinkService) normalizeSchedule(schedule repository.LinkSchedule) (*repository.LinkSchedule, error) {
+	schedule.Timezone = strings.TrimSpace(schedule.Timezone)
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, ValidationError("invalid schedule timezone")
+	}
+
+	var activeFrom, activeUntil time.Time
+	if schedule.ActiveFrom, activeFrom, err = normalizeScheduleTime(schedule.ActiveFrom, loc); err != nil {
+		return nil, err
+	}
+	if schedule.ActiveUntil, activeUntil, err = normalizeScheduleTime(schedule.ActiveUntil, loc); err != nil {
+		return nil, err
+	}
+	if schedule.ActiveFrom != "" && schedule.ActiveUntil != "" && !activeUntil.After(activeFrom) {
+		return nil, ValidationError("schedule ends before it starts")
+	}
+
+	if schedule.PendingURL != "" {
+		if schedule.PendingURL, err = s.checkDestination(schedule.PendingURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(schedule.Changes) > MaxScheduledChanges {
+		return nil, ValidationError("too many scheduled changes")
+	}
+	changes := make([]repository.ScheduledDestination, len(schedule.Changes))
+	for i, change := range schedule.Changes {
+		if change.At, _, err = normalizeScheduleTime(change.At, loc); err != nil {
+			return nil, err
+		}
+		if change.At == "" {
+			return nil, ValidationError("invalid schedule time")
+		}
+		if change.URL, err = s.checkDestination(change.URL); err != nil {
+			return nil, err
+		}
+		changes[i] = change
+	}
+	// The layout sorts lexically in time order.
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At < changes[j].At })
+	schedule.Changes = changes
+
+	return &schedule, nil
+}
+
+// normalizeScheduleTime parses a wall-clock time given with or without
+// seconds, or a plain date meaning its midnight, and formats it back with
+// scheduleLayout.
+func normalizeScheduleTime(value string, loc *time.Location) (string, time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", time.Time{}, nil
+	}
+
+	for _, layout := range scheduleInputLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.Format(scheduleLayout), t, nil
+		}
+	}
+	return "", time.Time{}, ValidationError("invalid schedule time")
+}
+
+// CheckSchedule reports whether the link can be visited now. A link that is
+// not active yet is still visitable when it has a pending URL to send
+// visitors to in the meantime.
+func (s *LinkService) CheckSchedule(link *repository.Link) error {
+	if link.Schedule == nil {
+		return nil
+	}
+
+	loc := scheduleLocation(link.Schedule)
+	now := time.Now()
+	if until, ok := parseScheduleTime(link.Schedule.ActiveUntil, loc); ok && !now.Before(until) {
+		return ErrLinkExpired
+	}
+	if scheduledPending(link.Schedule, now) && link.Schedule.PendingURL == "" {
+		return ErrLinkNotActive
+	}
+	return nil
+}
+
+// scheduledPending reports whether the schedule has not started yet.
+func scheduledPending(schedule *repository.LinkSchedule, now time.Time) bool {
+	if schedule == nil {
+		return false
+	}
+	from, ok := parseScheduleTime(schedule.ActiveFrom, scheduleLocation(schedule))
+	return ok && now.Before(from)
+}
+
+// scheduledURL returns the destination of the latest change that already took
+// effect, or the link's own URL.
+func scheduledURL(link *repository.Link, now time.Time) string {
+	if link.Schedule == nil {
+		return link.OriginalURL
+	}
+
+	loc := scheduleLocation(link.Schedule)
+	destination := link.OriginalURL
+	for _, change := range link.Schedule.Changes {
+		at, ok := parseScheduleTime(change.At, loc)
+		if !ok || now.Before(at) {
+			break
+		}
+		destination = change.URL
+	}
+	return destination
+}
+
+func scheduleLocation(schedule *repository.LinkSchedule) *time.Location {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func parseScheduleTime(value string, loc *time.Location) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(scheduleLayout, value, loc)
+	return t, err == nil
+}
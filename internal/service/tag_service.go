@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+const (
+	MaxTagLength   = 64
+	MaxTagsPerLink = 20
+)
+
+type TagServiceInterface interface {
+	GetTags(userID int) ([]*repository.Tag, error)
+	CreateTag(userID int, name string) (*repository.Tag, error)
+	RenameTag(id, userID int, name string) (*repository.Tag, error)
+	DeleteTag(id, userID int) error
+}
+
+type TagService struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository) *TagService {
+	return &TagService{tagRepo: tagRepo}
+}
+
+func (s *TagService) GetTags(userID int) ([]*repository.Tag, error) {
+	tags, err := s.tagRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []*repository.Tag{}
+	}
+	return tags, nil
+}
+
+func (s *TagService) CreateTag(userID int, name string) (*repository.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.Create(userID, name)
+}
+
+func (s *TagService) RenameTag(id, userID int, name string) (*repository.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tagRepo.Rename(id, userID, name)
+}
+
+func (s *TagService) DeleteTag(id, userID int) error {
+	return s.tagRepo.Delete(id, userID)
+}
+
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", ValidationError("tag name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxTagLength || strings.Contains(name, ",") {
+		return "", ValidationError("invalid tag name")
+	}
+	return name, nil
+}
+
+// normalizeTagNames validates the names and drops case-insensitive
+// duplicates, keeping the first spelling.
+func normalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, name)
+	}
+
+	if len(result) > MaxTagsPerLink {
+		return nil, ValidationError("too many tags")
+	}
+
+	return result, nil
+}
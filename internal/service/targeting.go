@@ -0,0 +1,214 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/mssola/useragent"
+)
+
+// MaxTargetingRules limits how many targeting rules a link can have.
+const MaxTargetingRules = 20
+
+// Values a targeting rule can match on.
+var (
+	targetingOS       = []string{"ios", "android", "windows", "macos", "linux", "chromeos", "other"}
+	targetingDevices  = []string{"mobile", "tablet", "desktop", "bot"}
+	targetingBrowsers = []string{"chrome", "safari", "firefox", "edge", "opera", "samsung", "other"}
+)
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	regionPattern  = regexp.MustCompile(`^[A-Z]{2}-[A-Z0-9]{1,3}$`)
+)
+
+// DeviceInfo is the visitor's platform as seen by targeting rules.
+type DeviceInfo struct {
+	OS      string
+	Device  string
+	Browser string
+}
+
+// ParseDevice classifies a User-Agent header into the values used by
+// targeting rules. Android tablets are told apart from phones by the missing
+// "Mobile" token, as Google recommends.
+func ParseDevice(userAgent string) DeviceInfo {
+	ua := useragent.New(userAgent)
+	os := strings.ToLower(ua.OS())
+	platform := strings.ToLower(ua.Platform())
+	browser, _ := ua.Browser()
+	browser = strings.ToLower(browser)
+
+	var info DeviceInfo
+	switch {
+	case strings.Contains(platform, "iphone"), strings.Contains(platform, "ipad"),
+		strings.Contains(platform, "ipod"), strings.Contains(os, "iphone os"), strings.Contains(os, "cpu os"):
+		info.OS = "ios"
+	case strings.Contains(os, "android"):
+		info.OS = "android"
+	case strings.Contains(os, "windows"):
+		info.OS = "windows"
+	case strings.Contains(os, "cros"):
+		info.OS = "chromeos"
+	case strings.Contains(os, "mac os"):
+		info.OS = "macos"
+	case strings.Contains(os, "linux"):
+		info.OS = "linux"
+	default:
+		info.OS = "other"
+	}
+
+	switch {
+	case ua.Bot():
+		info.Device = "bot"
+	case strings.Contains(platform, "ipad"), strings.Contains(strings.ToLower(userAgent), "tablet"),
+		info.OS == "android" && !strings.Contains(userAgent, "Mobile"):
+		info.Device = "tablet"
+	case ua.Mobile():
+		info.Device = "mobile"
+	default:
+		info.Device = "desktop"
+	}
+
+	switch {
+	case strings.Contains(browser, "edge"):
+		info.Browser = "edge"
+	case strings.Contains(browser, "opera"):
+		info.Browser = "opera"
+	case strings.Contains(browser, "samsung"):
+		info.Browser = "samsung"
+	case strings.Contains(browser, "chrom"):
+		info.Browser = "chrome"
+	case strings.Contains(browser, "firefox"):
+		info.Browser = "firefox"
+	case strings.Contains(browser, "safari"):
+		info.Browser = "safari"
+	default:
+		info.Browser = "other"
+	}
+
+	return info
+}
+
+// matchTargetingRule returns the first rule that applies to the device and
+// location.
+func matchTargetingRule(rules []repository.TargetingRule, device DeviceInfo, location GeoLocation) *repository.TargetingRule {
+	for i := range rules {
+		rule := &rules[i]
+		if matchesAny(rule.OS, device.OS) && matchesAny(rule.Device, device.Device) &&
+			matchesAny(rule.Browser, device.Browser) &&
+			matchesAny(rule.Country, location.Country) && matchesAny(rule.Region, location.Region) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// needsLocation reports whether any rule has a location condition, so the
+// visitor's IP only has to be resolved when it matters.
+func needsLocation(rules []repository.TargetingRule) bool {
+	for _, rule := range rules {
+		if len(rule.Country) > 0 || len(rule.Region) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether value is one of values. An empty list matches
+// everything.
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTargetingRules replaces the link's targeting rules. Rule destinations
+// go through the same URL, domain and threat checks as the link itself.
+func (s *LinkService) SetTargetingRules(id, userID int, rules []repository.TargetingRule) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	normalized, err := s.normalizeTargetingRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.SetTargetingRules(id, normalized); err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scanDestinations(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (s *LinkService) normalizeTargetingRules(rules []repository.TargetingRule) ([]repository.TargetingRule, error) {
+	if len(rules) > MaxTargetingRules {
+		return nil, ValidationError("too many targeting rules")
+	}
+
+	normalized := make([]repository.TargetingRule, len(rules))
+	for i, rule := range rules {
+		var err error
+		if rule.OS, err = normalizeTargetingValues(rule.OS, targetingOS, "os"); err != nil {
+			return nil, err
+		}
+		if rule.Device, err = normalizeTargetingValues(rule.Device, targetingDevices, "device"); err != nil {
+			return nil, err
+		}
+		if rule.Browser, err = normalizeTargetingValues(rule.Browser, targetingBrowsers, "browser"); err != nil {
+			return nil, err
+		}
+		if rule.Country, err = normalizeGeoValues(rule.Country, countryPattern, "country"); err != nil {
+			return nil, err
+		}
+		if rule.Region, err = normalizeGeoValues(rule.Region, regionPattern, "region"); err != nil {
+			return nil, err
+		}
+		if rule.URL, err = s.checkDestination(rule.URL); err != nil {
+			return nil, err
+		}
+		normalized[i] = rule
+	}
+
+	return normalized, nil
+}
+
+func normalizeTargetingValues(values, allowed []string, field string) ([]string, error) {
+	var normalized []string
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !matchesAny(allowed, value) {
+			return nil, ValidationError("invalid targeting " + field)
+		}
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+func normalizeGeoValues(values []string, pattern *regexp.Regexp, field string) ([]string, error) {
+	var normalized []string
+	for _, value := range values {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !pattern.MatchString(value) {
+			return nil, ValidationError("invalid targeting " + field)
+		}
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// ThreatScanner checks destination URLs against a threat-intelligence
+// provider. Scan returns the threat type of every flagged URL; clean URLs
+// are left out of the result.
+type ThreatScanner interface {
+	Scan(ctx context.Context, urls []string) (map[string]string, error)
+}
+
+// DefaultSafeBrowsingEndpoint is the Google Safe Browsing v4 Lookup API.
+const DefaultSafeBrowsingEndpoint = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// safeBrowsingBatchSize is the number of URLs the Lookup API accepts in a
+// single request.
+const safeBrowsingBatchSize = 500
+
+// SafeBrowsingScanner talks to the Safe Browsing v4 Lookup API or any
+// service that implements the same threatMatches:find endpoint.
+type SafeBrowsingScanner struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewSafeBrowsingScanner(endpoint, apiKey string, timeout time.Duration) *SafeBrowsingScanner {
+	if endpoint == "" {
+		endpoint = DefaultSafeBrowsingEndpoint
+	}
+
+	return &SafeBrowsingScanner{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+type safeBrowsingEntry struct {
+	URL string `json:"url"`
+}
+
+type safeBrowsingRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string            `json:"threatTypes"`
+		PlatformTypes    []string            `json:"platformTypes"`
+		ThreatEntryTypes []string            `json:"threatEntryTypes"`
+		ThreatEntries    []safeBrowsingEntry `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type safeBrowsingResponse struct {
+	Matches []struct {
+		ThreatType string            `json:"threatType"`
+		Threat     safeBrowsingEntry `json:"threat"`
+	} `json:"matches"`
+}
+
+func (s *SafeBrowsingScanner) Scan(ctx context.Context, urls []string) (map[string]string, error) {
+	threats := map[string]string{}
+
+	for start := 0; start < len(urls); start += safeBrowsingBatchSize {
+		end := start + safeBrowsingBatchSize
+		if end > len(urls) {
+			end = len(urls)
+		}
+		if err := s.scanBatch(ctx, urls[start:end], threats); err != nil {
+			return nil, err
+		}
+	}
+
+	return threats, nil
+}
+
+func (s *SafeBrowsingScanner) scanBatch(ctx context.Context, urls []string, threats map[string]string) error {
+	var body safeBrowsingRequest
+	body.Client.ClientID = "shortlink-go"
+	body.Client.ClientVersion = "1.0"
+	body.ThreatInfo.ThreatTypes = []string{
+		"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION",
+	}
+	body.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	body.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	for _, u := range urls {
+		body.ThreatInfo.ThreatEntries = append(body.ThreatInfo.ThreatEntries, safeBrowsingEntry{URL: u})
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := s.endpoint
+	if s.apiKey != "" {
+		endpoint += "?key=" + url.QueryEscape(s.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("safe browsing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("safe browsing returned status %d", resp.StatusCode)
+	}
+
+	var result safeBrowsingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode safe browsing response: %w", err)
+	}
+
+	for _, match := range result.Matches {
+		threats[match.Threat.URL] = strings.ToLower(match.ThreatType)
+	}
+
+	return nil
+}
+
+// ThreatRescanner periodically re-checks every destination of every link
+// with the scanner, since providers keep adding and removing URLs from their
+// lists.
+type ThreatRescanner struct {
+	linkRepo repository.LinkRepository
+	scanner  ThreatScanner
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func NewThreatRescanner(linkRepo repository.LinkRepository, scanner ThreatScanner, interval, timeout time.Duration) *ThreatRescanner {
+	return &ThreatRescanner{
+		linkRepo: linkRepo,
+		scanner:  scanner,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Run rescans links every interval until ctx is cancelled.
+func (s *ThreatRescanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.rescan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ThreatRescanner) rescan(ctx context.Context) {
+	var batch []*repository.Link
+	var urls []string
+	var flagged, cleared int
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		scanCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		threats, err := s.scanner.Scan(scanCtx, urls)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		for _, link := range batch {
+			wasFlagged := isFlagged(link)
+
+			threat := threats[link.OriginalURL]
+			if threat != link.ThreatType {
+				if err := s.linkRepo.SetThreat(link.ID, threat); err != nil {
+					return err
+				}
+				link.ThreatType = threat
+			}
+
+			destinations := map[string]string{}
+			for _, url := range extraDestinations(link) {
+				if threat := threats[url]; threat != "" {
+					destinations[url] = threat
+				}
+			}
+			if !maps.Equal(destinations, link.DestinationThreats) {
+				if err := s.linkRepo.SetDestinationThreats(link.ID, destinations); err != nil {
+					return err
+				}
+				link.DestinationThreats = destinations
+			}
+
+			switch {
+			case isFlagged(link) && !wasFlagged:
+				flagged++
+			case !isFlagged(link) && wasFlagged:
+				cleared++
+			}
+		}
+
+		batch = batch[:0]
+		urls = urls[:0]
+		return nil
+	}
+
+	err := s.linkRepo.EachLink(func(link *repository.Link) error {
+		batch = append(batch, link)
+		urls = append(urls, linkDestinations(link)...)
+		if len(urls) < safeBrowsingBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		log.Printf("Failed to rescan links for threats: %v", err)
+	}
+	if flagged > 0 || cleared > 0 {
+		log.Printf("Threat rescan flagged %d and cleared %d links", flagged, cleared)
+	}
+}
+
+// isFlagged reports whether any destination of the link has been flagged.
+func isFlagged(link *repository.Link) bool {
+	return link.ThreatType != "" || len(link.DestinationThreats) > 0
+}
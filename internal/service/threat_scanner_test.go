@@ -0,0 +1,646 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// fakeThreatScanner flags URLs that contain any of the markers and counts
+// how often it is called.
+type fakeThreatScanner struct {
+	markers []string
+	err     error
+	calls   int
+}
+
+func (s *fakeThreatScanner) Scan(_ context.Context, urls []string) (map[string]string, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	threats := map[string]string{}
+	for _, u := range urls {
+		for _, marker := range s.markers {
+			if strings.Contains(u, marker) {
+				threats[u] = "social_engineering"
+				break
+			}
+		}
+	}
+	return threats, nil
+}
+
+// memoryLinkRepository keeps links in memory. Methods the tests do not use
+// are left to the embedded interface and panic when called.
+type memoryLinkRepository struct {
+	repository.LinkRepository
+	links    map[int]*repository.Link
+	versions map[int][]*repository.LinkVersion
+	nextID   int
+}
+
+func newMemoryLinkRepository() *memoryLinkRepository {
+	return &memoryLinkRepository{links: map[int]*repository.Link{}, versions: map[int][]*repository.LinkVersion{}}
+}
+
+func (r *memoryLinkRepository) Create(link *repository.Link) (*repository.Link, error) {
+	r.nextID++
+	stored := *link
+	stored.ID = r.nextID
+	r.links[stored.ID] = &stored
+	return r.FindByID(stored.ID)
+}
+
+func (r *memoryLinkRepository) FindByID(id int) (*repository.Link, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, errors.New("link not found")
+	}
+	copied := *link
+	return &copied, nil
+}
+
+func (r *memoryLinkRepository) FindByURLAndUser(url string, userID int, domainID *int) (*repository.Link, error) {
+	for _, link := range r.links {
+		if link.OriginalURL == url && link.UserID == userID && link.DomainID == nil && domainID == nil {
+			return r.FindByID(link.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryLinkRepository) ShortCodeExists(domainID *int, shortCode string) (bool, error) {
+	for _, link := range r.links {
+		if link.ShortCode == shortCode {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryLinkRepository) Update(id int, update repository.LinkUpdate) (*repository.Link, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, errors.New("link not found")
+	}
+	if update.OriginalURL != nil {
+		link.OriginalURL = *update.OriginalURL
+	}
+	if update.Title != nil {
+		link.Title = *update.Title
+	}
+	if update.UTMSource != nil {
+		link.UTMSource = *update.UTMSource
+	}
+	if update.Routing != nil {
+		link.TargetingRules = update.Routing.TargetingRules
+		link.Variants = update.Routing.Variants
+		link.Schedule = update.Routing.Schedule
+		link.DeepLink = update.Routing.DeepLink
+	}
+	return r.FindByID(id)
+}
+
+func (r *memoryLinkRepository) FindVersion(linkID, version int) (*repository.LinkVersion, error) {
+	for _, v := range r.versions[linkID] {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, repository.ErrVersionNotFound
+}
+
+func (r *memoryLinkRepository) SetThreat(id int, threatType string) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.ThreatType = threatType
+	return nil
+}
+
+func (r *memoryLinkRepository) SetDestinationThreats(id int, threats map[string]string) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.DestinationThreats = threats
+	return nil
+}
+
+func (r *memoryLinkRepository) SetTargetingRules(id int, rules []repository.TargetingRule) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.TargetingRules = rules
+	return nil
+}
+
+func (r *memoryLinkRepository) SetVariants(id int, variants []repository.LinkVariant) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.Variants = variants
+	return nil
+}
+
+func (r *memoryLinkRepository) SetSchedule(id int, schedule *repository.LinkSchedule) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.Schedule = schedule
+	return nil
+}
+
+func (r *memoryLinkRepository) SetDeepLink(id int, deepLink *repository.DeepLink) error {
+	link, ok := r.links[id]
+	if !ok {
+		return errors.New("link not found")
+	}
+	link.DeepLink = deepLink
+	return nil
+}
+
+func (r *memoryLinkRepository) EachLink(fn func(*repository.Link) error) error {
+	for id := 1; id <= r.nextID; id++ {
+		if link, ok := r.links[id]; ok {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryLinkRepository) FindActiveAfter(afterID, limit int) ([]*repository.Link, error) {
+	var links []*repository.Link
+	for id := afterID + 1; id <= r.nextID && len(links) < limit; id++ {
+		link, ok := r.links[id]
+		if !ok || link.DeletedAt != nil || link.DisabledReason != "" {
+			continue
+		}
+		copied := *link
+		links = append(links, &copied)
+	}
+	return links, nil
+}
+
+func newThreatTestService(scanner ThreatScanner) (*LinkService, *memoryLinkRepository) {
+	repo := newMemoryLinkRepository()
+	s := NewLinkService(
+		repo, nil, nil, nil, nil,
+		NewURLPolicy(nil, 0, false), NewDomainFilter("", nil), scanner, nil, nil, nil, nil,
+	)
+	return s, repo
+}
+
+func TestSafeBrowsingScanner(t *testing.T) {
+	var requests []safeBrowsingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("key") != "secret" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+
+		var req safeBrowsingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		matches := []map[string]interface{}{}
+		for _, entry := range req.ThreatInfo.ThreatEntries {
+			if strings.Contains(entry.URL, "evil") {
+				matches = append(matches, map[string]interface{}{"threatType": "MALWARE", "threat": entry})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"matches": matches})
+	}))
+	defer server.Close()
+
+	scanner := NewSafeBrowsingScanner(server.URL, "secret", time.Second)
+
+	urls := make([]string, safeBrowsingBatchSize+1)
+	for i := range urls {
+		urls[i] = "https://example.com/" + strings.Repeat("a", i%5)
+	}
+	urls[3] = "https://evil.example/"
+	urls[safeBrowsingBatchSize] = "https://evil.example/last"
+
+	threats, err := scanner.Scan(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("got %d requests, want 2 batches", len(requests))
+	}
+	want := map[string]string{
+		"https://evil.example/":     "malware",
+		"https://evil.example/last": "malware",
+	}
+	if len(threats) != len(want) {
+		t.Errorf("got threats %v, want %v", threats, want)
+	}
+	for url, threat := range want {
+		if threats[url] != threat {
+			t.Errorf("threats[%q] = %q, want %q", url, threats[url], threat)
+		}
+	}
+}
+
+func TestSafeBrowsingScannerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	scanner := NewSafeBrowsingScanner(server.URL, "", time.Second)
+	if _, err := scanner.Scan(context.Background(), []string{"https://example.com/"}); err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
+
+func TestFakeThreatScanner(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+
+	threats, err := scanner.Scan(context.Background(), []string{"https://phish.example/", "https://example.com/"})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if threats["https://phish.example/"] != "social_engineering" || len(threats) != 1 {
+		t.Errorf("unexpected threats %v", threats)
+	}
+}
+
+func TestCreateFlagsThreats(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, _ := newThreatTestService(scanner)
+
+	flagged, err := s.Create(CreateLinkInput{URL: "https://phish.example/login"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if flagged.ThreatType != "social_engineering" {
+		t.Errorf("ThreatType = %q, want social_engineering", flagged.ThreatType)
+	}
+
+	clean, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if clean.ThreatType != "" {
+		t.Errorf("ThreatType = %q, want none", clean.ThreatType)
+	}
+}
+
+func TestCreateWhenScannerFails(t *testing.T) {
+	s, _ := newThreatTestService(&fakeThreatScanner{err: errors.New("unavailable")})
+
+	link, err := s.Create(CreateLinkInput{URL: "https://phish.example/"}, 1)
+	if err != nil {
+		t.Fatalf("Create should not fail when the scanner does: %v", err)
+	}
+	if link.ThreatType != "" {
+		t.Errorf("ThreatType = %q, want none", link.ThreatType)
+	}
+}
+
+func TestCreateBatchScansOnce(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, _ := newThreatTestService(scanner)
+
+	results, err := s.CreateBatch([]CreateLinkInput{
+		{URL: "https://example.com/a"},
+		{URL: "HTTPS://Phish.example"},
+		{URL: "not a url"},
+	}, 1)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	if scanner.calls != 1 {
+		t.Errorf("scanner called %d times, want 1", scanner.calls)
+	}
+	if results[0].Err != nil || results[0].Link.ThreatType != "" {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Link.ThreatType != "social_engineering" {
+		t.Errorf("unexpected second result %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("invalid url should fail")
+	}
+}
+
+func TestCreateBatchReusesExisting(t *testing.T) {
+	s, _ := newThreatTestService(&fakeThreatScanner{})
+
+	existing, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	results, err := s.CreateBatch([]CreateLinkInput{
+		{URL: "https://example.com/"},
+		{URL: "https://example.com/", Title: "Spring sale", UTMSource: "newsletter"},
+		{URL: "https://example.com/other"},
+	}, 1)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	if !results[0].Existing || results[0].Link.ID != existing.ID {
+		t.Errorf("plain duplicate should reuse the link: %+v", results[0])
+	}
+	if results[1].Existing || results[1].Link.ID == existing.ID || results[1].Link.UTMSource != "newsletter" {
+		t.Errorf("duplicate with settings should create a new link: %+v", results[1])
+	}
+	if results[2].Existing {
+		t.Errorf("new url marked as existing: %+v", results[2])
+	}
+}
+
+type failingFindLinkRepository struct {
+	*memoryLinkRepository
+}
+
+func (failingFindLinkRepository) FindByURLAndUser(string, int, *int) (*repository.Link, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestCreateFailsWhenLookupFails(t *testing.T) {
+	s, repo := newThreatTestService(&fakeThreatScanner{})
+	s.linkRepo = failingFindLinkRepository{repo}
+
+	if _, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1); err == nil {
+		t.Error("Create should fail when the duplicate lookup fails")
+	}
+	if len(repo.links) != 0 {
+		t.Error("no link should be created when the duplicate lookup fails")
+	}
+}
+
+func TestUpdateRescansChangedURL(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, repo := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	title := "renamed"
+	if _, err := s.UpdateLink(link.ID, 1, repository.LinkUpdate{Title: &title}); err != nil {
+		t.Fatalf("UpdateLink: %v", err)
+	}
+	if scanner.calls != 1 {
+		t.Errorf("scanner called %d times, want 1: the url did not change", scanner.calls)
+	}
+
+	url := "https://phish.example/"
+	updated, err := s.UpdateLink(link.ID, 1, repository.LinkUpdate{OriginalURL: &url})
+	if err != nil {
+		t.Fatalf("UpdateLink: %v", err)
+	}
+	if updated.ThreatType != "social_engineering" || repo.links[link.ID].ThreatType != "social_engineering" {
+		t.Errorf("changed url was not flagged: %q", updated.ThreatType)
+	}
+}
+
+func TestThreatRescannerFlagsAndClears(t *testing.T) {
+	repo := newMemoryLinkRepository()
+	repo.Create(&repository.Link{OriginalURL: "https://phish.example/"})
+	repo.Create(&repository.Link{OriginalURL: "https://example.com/", ThreatType: "malware"})
+	repo.Create(&repository.Link{OriginalURL: "https://example.org/"})
+
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	NewThreatRescanner(repo, scanner, time.Hour, time.Second).rescan(context.Background())
+
+	want := map[int]string{1: "social_engineering", 2: "", 3: ""}
+	for id, threat := range want {
+		if repo.links[id].ThreatType != threat {
+			t.Errorf("link %d ThreatType = %q, want %q", id, repo.links[id].ThreatType, threat)
+		}
+	}
+	if scanner.calls != 1 {
+		t.Errorf("scanner called %d times, want one batch", scanner.calls)
+	}
+}
+
+func TestThreatRescannerKeepsStateOnError(t *testing.T) {
+	repo := newMemoryLinkRepository()
+	repo.Create(&repository.Link{OriginalURL: "https://example.com/", ThreatType: "malware"})
+
+	scanner := &fakeThreatScanner{err: errors.New("unavailable")}
+	NewThreatRescanner(repo, scanner, time.Hour, time.Second).rescan(context.Background())
+
+	if repo.links[1].ThreatType != "malware" {
+		t.Errorf("ThreatType = %q, want it unchanged", repo.links[1].ThreatType)
+	}
+}
+
+func TestSetTargetingRulesScansDestinations(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, repo := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	link, err = s.SetTargetingRules(link.ID, 1, []repository.TargetingRule{
+		{OS: []string{"ios"}, URL: "https://phish.example/ios"},
+		{OS: []string{"android"}, URL: "https://example.com/android"},
+	})
+	if err != nil {
+		t.Fatalf("SetTargetingRules: %v", err)
+	}
+
+	want := map[string]string{"https://phish.example/ios": "social_engineering"}
+	if len(link.DestinationThreats) != 1 || link.DestinationThreats["https://phish.example/ios"] != "social_engineering" {
+		t.Errorf("DestinationThreats = %v, want %v", link.DestinationThreats, want)
+	}
+	if len(repo.links[link.ID].DestinationThreats) != 1 {
+		t.Errorf("destination threats were not stored: %v", repo.links[link.ID].DestinationThreats)
+	}
+	if link.ThreatType != "" {
+		t.Errorf("ThreatType = %q, want the link's own url to stay clean", link.ThreatType)
+	}
+
+	iphone := Visit{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"}
+	if threat := s.DestinationThreat(link, iphone); threat != "social_engineering" {
+		t.Errorf("DestinationThreat for iOS = %q, want social_engineering", threat)
+	}
+	if threat := s.DestinationThreat(link, Visit{UserAgent: "Mozilla/5.0 (X11; Linux x86_64)"}); threat != "" {
+		t.Errorf("DestinationThreat for desktop = %q, want none", threat)
+	}
+}
+
+func TestThreatRescannerChecksRuleDestinations(t *testing.T) {
+	repo := newMemoryLinkRepository()
+	repo.Create(&repository.Link{
+		OriginalURL:    "https://example.com/",
+		TargetingRules: []repository.TargetingRule{{URL: "https://phish.example/"}},
+	})
+	repo.Create(&repository.Link{
+		OriginalURL:        "https://example.org/",
+		TargetingRules:     []repository.TargetingRule{{URL: "https://example.org/rule"}},
+		DestinationThreats: map[string]string{"https://example.org/rule": "malware"},
+	})
+
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	NewThreatRescanner(repo, scanner, time.Hour, time.Second).rescan(context.Background())
+
+	if repo.links[1].DestinationThreats["https://phish.example/"] != "social_engineering" {
+		t.Errorf("rule destination was not flagged: %v", repo.links[1].DestinationThreats)
+	}
+	if repo.links[1].ThreatType != "" {
+		t.Errorf("ThreatType = %q, want none", repo.links[1].ThreatType)
+	}
+	if len(repo.links[2].DestinationThreats) != 0 {
+		t.Errorf("clean rule destination was not cleared: %v", repo.links[2].DestinationThreats)
+	}
+}
+
+func TestSetVariantsScansDestinations(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, _ := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	link, err = s.SetVariants(link.ID, 1, []repository.LinkVariant{
+		{Name: "a", URL: "https://example.com/a", Weight: 1},
+		{Name: "b", URL: "https://phish.example/b", Weight: 1},
+	})
+	if err != nil {
+		t.Fatalf("SetVariants: %v", err)
+	}
+
+	if link.DestinationThreats["https://phish.example/b"] != "social_engineering" || len(link.DestinationThreats) != 1 {
+		t.Errorf("unexpected DestinationThreats %v", link.DestinationThreats)
+	}
+	if threat := s.DestinationThreat(link, Visit{Variant: "b"}); threat != "social_engineering" {
+		t.Errorf("DestinationThreat for variant b = %q, want social_engineering", threat)
+	}
+	if threat := s.DestinationThreat(link, Visit{Variant: "a"}); threat != "" {
+		t.Errorf("DestinationThreat for variant a = %q, want none", threat)
+	}
+}
+
+func TestSetScheduleScansDestinations(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, _ := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	link, err = s.SetSchedule(link.ID, 1, &repository.LinkSchedule{
+		ActiveFrom: "2000-01-01T00:00",
+		PendingURL: "https://phish.example/soon",
+		Changes: []repository.ScheduledDestination{
+			{At: "2001-01-01T00:00", URL: "https://phish.example/later"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SetSchedule: %v", err)
+	}
+
+	want := []string{"https://phish.example/soon", "https://phish.example/later"}
+	for _, url := range want {
+		if link.DestinationThreats[url] != "social_engineering" {
+			t.Errorf("%s was not flagged: %v", url, link.DestinationThreats)
+		}
+	}
+	// The change already took effect, so every visitor goes to its URL.
+	if threat := s.DestinationThreat(link, Visit{}); threat != "social_engineering" {
+		t.Errorf("DestinationThreat = %q, want social_engineering", threat)
+	}
+}
+
+func TestSetDeepLinkScansDestinations(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, _ := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	link, err = s.SetDeepLink(link.ID, 1, &repository.DeepLink{
+		IOSURL:     "https://example.com/ios",
+		AndroidURL: "https://phish.example/android",
+	})
+	if err != nil {
+		t.Fatalf("SetDeepLink: %v", err)
+	}
+
+	if link.DestinationThreats["https://phish.example/android"] != "social_engineering" || len(link.DestinationThreats) != 1 {
+		t.Errorf("unexpected DestinationThreats %v", link.DestinationThreats)
+	}
+	android := Visit{UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"}
+	if threat := s.DestinationThreat(link, android); threat != "social_engineering" {
+		t.Errorf("DestinationThreat for Android = %q, want social_engineering", threat)
+	}
+}
+
+func TestRevertLinkRestoresSettings(t *testing.T) {
+	scanner := &fakeThreatScanner{markers: []string{"phish"}}
+	s, repo := newThreatTestService(scanner)
+
+	link, err := s.Create(CreateLinkInput{URL: "https://example.com/"}, 1)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	repo.versions[link.ID] = []*repository.LinkVersion{
+		{
+			LinkID: link.ID, Version: 1, OriginalURL: "https://example.com/old", ShortCode: link.ShortCode,
+			Settings: &repository.LinkSettings{
+				UTMSource: "newsletter",
+				LinkRouting: repository.LinkRouting{
+					Variants: []repository.LinkVariant{
+						{Name: "a", URL: "https://example.com/a", Weight: 1},
+						{Name: "b", URL: "https://phish.example/b", Weight: 1},
+					},
+				},
+			},
+		},
+		// Recorded before settings were versioned.
+		{LinkID: link.ID, Version: 2, OriginalURL: "https://example.com/older", ShortCode: link.ShortCode},
+	}
+
+	link, err = s.RevertLink(link.ID, 1, 1)
+	if err != nil {
+		t.Fatalf("RevertLink: %v", err)
+	}
+	if link.OriginalURL != "https://example.com/old" || link.UTMSource != "newsletter" || len(link.Variants) != 2 {
+		t.Errorf("settings were not restored: %+v", link)
+	}
+	if link.DestinationThreats["https://phish.example/b"] != "social_engineering" {
+		t.Errorf("restored variants were not scanned: %v", link.DestinationThreats)
+	}
+
+	link, err = s.RevertLink(link.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("RevertLink: %v", err)
+	}
+	if link.OriginalURL != "https://example.com/older" || link.UTMSource != "newsletter" || len(link.Variants) != 2 {
+		t.Errorf("a version without settings should keep the current settings: %+v", link)
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// TitleFetcher reads the title of the page behind a URL.
+type TitleFetcher interface {
+	FetchTitle(ctx context.Context, url string) (string, error)
+}
+
+type titleJob struct {
+	linkID int
+	url    string
+}
+
+// TitleResolver fills in missing link titles from the destination page's
+// <title>. Jobs are processed by a fixed pool of workers; when the queue is
+// full new jobs are dropped rather than slowing down link creation.
+type TitleResolver struct {
+	linkRepo repository.LinkRepository
+	fetcher  TitleFetcher
+	jobs     chan titleJob
+	workers  int
+	timeout  time.Duration
+}
+
+func NewTitleResolver(linkRepo repository.LinkRepository, fetcher TitleFetcher, workers int, timeout time.Duration) *TitleResolver {
+	return &TitleResolver{
+		linkRepo: linkRepo,
+		fetcher:  fetcher,
+		jobs:     make(chan titleJob, 1000),
+		workers:  workers,
+		timeout:  timeout,
+	}
+}
+
+func (r *TitleResolver) Enqueue(linkID int, url string) {
+	select {
+	case r.jobs <- titleJob{linkID: linkID, url: url}:
+	default:
+		log.Printf("Title queue is full, skipping link %d", linkID)
+	}
+}
+
+// Run starts the workers and blocks until ctx is cancelled.
+func (r *TitleResolver) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for i := 0; i < r.workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-r.jobs:
+					r.resolve(ctx, job)
+				}
+			}
+		}()
+	}
+	for i := 0; i < r.workers; i++ {
+		<-done
+	}
+}
+
+func (r *TitleResolver) resolve(ctx context.Context, job titleJob) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	title, err := r.fetcher.FetchTitle(ctx, job.url)
+	if err != nil {
+		log.Printf("Failed to fetch title for link %d: %v", job.linkID, err)
+		return
+	}
+
+	title = truncateRunes(title, MaxTitleLength)
+	if title == "" {
+		return
+	}
+
+	if err := r.linkRepo.SetTitleIfEmpty(job.linkID, title); err != nil {
+		log.Printf("Failed to save title for link %d: %v", job.linkID, err)
+	}
+}
+
+// maxTitlePageSize bounds how much of the destination page is read while
+// looking for <title>.
+const maxTitlePageSize = 512 << 10
+
+type HTTPTitleFetcher struct {
+	client *http.Client
+}
+
+func NewHTTPTitleFetcher(timeout time.Duration) *HTTPTitleFetcher {
+	return &HTTPTitleFetcher{client: newPublicHTTPClient(timeout)}
+}
+
+func (f *HTTPTitleFetcher) FetchTitle(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "shortLink-go/1.0 (+title fetcher)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxTitlePageSize), contentType)
+	if err != nil {
+		return "", err
+	}
+
+	return parseTitle(body)
+}
+
+func parseTitle(body io.Reader) (string, error) {
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return "", err
+			}
+			return "", errors.New("title not found")
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			inTitle = string(name) == "title"
+		case html.TextToken:
+			if inTitle {
+				return strings.Join(strings.Fields(string(tokenizer.Text())), " "), nil
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "head" {
+				return "", errors.New("title not found")
+			}
+			inTitle = false
+		}
+	}
+}
+
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
+
+// newPublicHTTPClient returns a client for requests to user-supplied URLs.
+// It refuses to connect to loopback, private and link-local addresses, so
+// links cannot be used to probe the service's internal network.
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// nonPublicNetworks are special-purpose ranges the net.IP helpers do not
+// cover: shared address space used by carrier-grade NAT, IETF protocol
+// assignments, benchmarking and local-use NAT64.
+var nonPublicNetworks = mustParseCIDRs(
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"64:ff9b:1::/48",
+)
+
+// Prefixes of IPv6 addresses that embed an IPv4 address: well-known NAT64
+// keeps it in the last four bytes and 6to4 right after the prefix.
+var (
+	nat64Network = mustParseCIDRs("64:ff9b::/96")[0]
+	sixToFourNet = mustParseCIDRs("2002::/16")[0]
+)
+
+func isPublicIP(ip net.IP) bool {
+	if ip.To4() == nil && len(ip) == net.IPv6len {
+		switch {
+		case nat64Network.Contains(ip):
+			return isPublicIP(net.IP(ip[12:16]))
+		case sixToFourNet.Contains(ip):
+			return isPublicIP(net.IP(ip[2:6]))
+		}
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
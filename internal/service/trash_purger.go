@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// TrashPurger permanently removes links that have been in the trash for
+// longer than the retention period.
+type TrashPurger struct {
+	linkRepo  repository.LinkRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(linkRepo repository.LinkRepository, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		linkRepo:  linkRepo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run purges expired links every interval until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge() {
+	purged, err := p.linkRepo.PurgeDeleted(time.Now().Add(-p.retention))
+	if err != nil {
+		log.Printf("Failed to purge deleted links: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d deleted links", purged)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/pkg/hyperloglog"
+)
+
+// VisitorCounter estimates unique visitors of links per UTC day with
+// HyperLogLog sketches.
+//
+// Visitors are told apart by a keyed hash of their IP address and
+// User-Agent. The key is a random salt that changes every day and is deleted
+// once the day is over, so neither the sketches nor the database can be used
+// to recognise a visitor later, even by someone who knows their address.
+type VisitorCounter struct {
+	visitorRepo repository.VisitorRepository
+
+	mu   sync.Mutex
+	day  time.Time
+	salt []byte
+}
+
+func NewVisitorCounter(visitorRepo repository.VisitorRepository) *VisitorCounter {
+	return &VisitorCounter{visitorRepo: visitorRepo}
+}
+
+// Record counts the visit towards the link's unique visitors of the day.
+func (c *VisitorCounter) Record(linkID int, visit Visit) error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	salt, err := c.saltFor(day)
+	if err != nil {
+		return err
+	}
+
+	index, rank := hyperloglog.Position(visitorHash(salt, visit))
+	return c.visitorRepo.Record(linkID, day, index, rank)
+}
+
+// Stats estimates the link's unique visitors for every day of the period
+// and for the whole period, by merging the days' sketches.
+func (c *VisitorCounter) Stats(linkID int, from, to *time.Time) (*repository.VisitorStats, error) {
+	days, err := c.visitorRepo.FindByLink(linkID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &repository.VisitorStats{Days: []*repository.DailyVisitorsCount{}}
+	total := hyperloglog.New()
+	for _, day := range days {
+		sketch, err := hyperloglog.FromBytes(day.Registers)
+		if err != nil {
+			log.Printf("Skipping visitors of link %d on %s: %v", linkID, day.Day.Format(time.DateOnly), err)
+			continue
+		}
+		total.Merge(sketch)
+		stats.Days = append(stats.Days, &repository.DailyVisitorsCount{
+			Date:           day.Day.Format(time.DateOnly),
+			UniqueVisitors: sketch.Estimate(),
+		})
+	}
+	stats.UniqueVisitors = total.Estimate()
+
+	return stats, nil
+}
+
+// saltFor returns the salt of the day, shared by every instance through the
+// database. Salts of earlier days are deleted when the day changes.
+func (c *VisitorCounter) saltFor(day time.Time) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.salt != nil && c.day.Equal(day) {
+		return c.salt, nil
+	}
+
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, err
+	}
+	salt, err := c.visitorRepo.Salt(day, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.visitorRepo.DeleteSaltsBefore(day); err != nil {
+		log.Printf("Failed to delete old visitor salts: %v", err)
+	}
+
+	c.day, c.salt = day, salt
+	return salt, nil
+}
+
+func visitorHash(salt []byte, visit Visit) uint64 {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(visit.IP)
+	mac.Write([]byte{0})
+	mac.Write([]byte(visit.UserAgent))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
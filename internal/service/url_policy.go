@@ -0,0 +1,151 @@
+package service
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// DefaultMaxURLLength is used when the policy is created without a limit.
+const DefaultMaxURLLength = 2048
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// URLPolicy validates destination URLs and brings them to a canonical form,
+// so that equivalent URLs are stored, and deduplicated, as the same string.
+type URLPolicy struct {
+	schemes   map[string]bool
+	maxLength int
+	sortQuery bool
+}
+
+// NewURLPolicy creates a policy that accepts the given schemes. With no
+// schemes, only http and https are allowed. With sortQuery, query parameters
+// are sorted by name, so URLs that differ only in parameter order are
+// deduplicated; leave it off for destinations that depend on the order.
+func NewURLPolicy(schemes []string, maxLength int, sortQuery bool) *URLPolicy {
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxURLLength
+	}
+
+	allowed := make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		allowed[strings.ToLower(strings.TrimSpace(scheme))] = true
+	}
+
+	return &URLPolicy{schemes: allowed, maxLength: maxLength, sortQuery: sortQuery}
+}
+
+// Normalize validates the URL and returns its canonical form: the host is
+// lowercased and converted to punycode, default ports are removed, an empty
+// path becomes "/" and empty query parameters are dropped. Parameters keep
+// their order unless the policy sorts them.
+func (p *URLPolicy) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ValidationError("url is required")
+	}
+	if len(raw) > p.maxLength {
+		return "", ValidationError("url is too long")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() {
+		return "", ValidationError("invalid url")
+	}
+	if !p.schemes[u.Scheme] {
+		return "", ValidationError("url scheme is not allowed")
+	}
+	if u.Opaque != "" {
+		return "", ValidationError("invalid url")
+	}
+	if u.User != nil {
+		return "", ValidationError("url must not contain credentials")
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return "", err
+	}
+
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+	u.RawQuery = cleanQuery(u.RawQuery, p.sortQuery)
+	u.ForceQuery = false
+
+	normalized := u.String()
+	if len(normalized) > p.maxLength {
+		return "", ValidationError("url is too long")
+	}
+
+	return normalized, nil
+}
+
+func normalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return "", ValidationError("invalid url")
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", ValidationError("invalid url host")
+	}
+
+	return ascii, nil
+}
+
+// cleanQuery drops empty parameters, such as the ones left by "&&", keeping
+// the encoding of the others. When sorted, parameters are ordered by name and
+// repeated ones keep their relative order.
+func cleanQuery(rawQuery string, sorted bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param != "" {
+			params = append(params, param)
+		}
+	}
+	if sorted {
+		sort.SliceStable(params, func(i, j int) bool {
+			return queryParamName(params[i]) < queryParamName(params[j])
+		})
+	}
+
+	return strings.Join(params, "&")
+}
+
+func queryParamName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	return name
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+// Limits for A/B variants.
+const (
+	MaxVariants      = 10
+	MaxVariantWeight = 1000
+)
+
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// SetVariants replaces the link's A/B variants. An empty list sends every
+// visitor to the link's own URL again. Variant URLs go through the same URL,
+// domain and threat checks as the link itself.
+func (s *LinkService) SetVariants(id, userID int, variants []repository.LinkVariant) (*repository.Link, error) {
+	if _, err := s.GetLink(id, userID); err != nil {
+		return nil, err
+	}
+
+	normalized, err := s.normalizeVariants(variants)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.SetVariants(id, normalized); err != nil {
+		return nil, err
+	}
+
+	link, err := s.linkRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scanDestinations(link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (s *LinkService) normalizeVariants(variants []repository.LinkVariant) ([]repository.LinkVariant, error) {
+	if len(variants) > MaxVariants {
+		return nil, ValidationError("too many variants")
+	}
+
+	names := map[string]bool{}
+	total := 0
+	normalized := make([]repository.LinkVariant, len(variants))
+	for i, variant := range variants {
+		variant.Name = strings.TrimSpace(variant.Name)
+		if !variantNamePattern.MatchString(variant.Name) {
+			return nil, ValidationError("invalid variant name")
+		}
+		if names[strings.ToLower(variant.Name)] {
+			return nil, ValidationError("duplicate variant name")
+		}
+		names[strings.ToLower(variant.Name)] = true
+
+		if variant.Weight < 0 || variant.Weight > MaxVariantWeight {
+			return nil, ValidationError("invalid variant weight")
+		}
+		total += variant.Weight
+
+		url, err := s.checkDestination(variant.URL)
+		if err != nil {
+			return nil, err
+		}
+		variant.URL = url
+		normalized[i] = variant
+	}
+	if len(variants) > 0 && total == 0 {
+		return nil, ValidationError("invalid variant weight")
+	}
+
+	return normalized, nil
+}
+
+// ChooseVariant returns the name of the variant the visitor is sent to, or
+// an empty string when the link has no variants, has not started yet, or a
+// deep link or targeting rule applies.
+// A variant named in visit.Variant, usually remembered in a cookie, is kept
+// as long as it still gets traffic. Otherwise the choice is made by hashing
+// the visitor's IP, so a visitor without cookies keeps getting the same
+// variant as well.
+func (s *LinkService) ChooseVariant(link *repository.Link, visit Visit) string {
+	if len(link.Variants) == 0 || scheduledPending(link.Schedule, time.Now()) ||
+		deepLinkURL(link, visit) != "" || s.matchRule(link, visit) != nil {
+		return ""
+	}
+
+	if variant := findVariant(link.Variants, visit.Variant); variant != nil && variant.Weight > 0 {
+		return variant.Name
+	}
+
+	total := 0
+	for _, variant := range link.Variants {
+		total += variant.Weight
+	}
+	if total == 0 {
+		return ""
+	}
+
+	hash := fnv.New64a()
+	binary.Write(hash, binary.BigEndian, int64(link.ID))
+	hash.Write(visit.IP)
+	point := int(hash.Sum64() % uint64(total))
+
+	for _, variant := range link.Variants {
+		if point < variant.Weight {
+			return variant.Name
+		}
+		point -= variant.Weight
+	}
+	return ""
+}
+
+// GetVariantStats reports clicks per variant. Current variants are listed in
+// order, including those without clicks, followed by removed variants that
+// still have clicks in the period. Clicks by bots are only counted when
+// includeBots is set.
+func (s *LinkService) GetVariantStats(id, userID int, from, to *time.Time, includeBots bool) ([]*repository.VariantCount, error) {
+	link, err := s.GetLink(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.clickRepo.CountByVariant(repository.ClickStatsQuery{
+		LinkID: id, From: from, To: to, IncludeBots: includeBots,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clicks := map[string]int{}
+	for _, count := range counts {
+		clicks[count.Variant] = count.Clicks
+	}
+
+	stats := []*repository.VariantCount{}
+	for _, variant := range link.Variants {
+		stats = append(stats, &repository.VariantCount{Variant: variant.Name, Clicks: clicks[variant.Name]})
+		delete(clicks, variant.Name)
+	}
+	for _, count := range counts {
+		if _, removed := clicks[count.Variant]; removed {
+			stats = append(stats, count)
+		}
+	}
+
+	return stats, nil
+}
+
+func findVariant(variants []repository.LinkVariant, name string) *repository.LinkVariant {
+	if name == "" {
+		return nil
+	}
+	for i := range variants {
+		if variants[i].Name == name {
+			return &variants[i]
+		}
+	}
+	return nil
+}
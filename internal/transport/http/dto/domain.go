@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+type DomainRequest struct {
+	Host string `json:"host"`
+}
+
+type DomainRuleRequest struct {
+	Action  string `json:"action"`
+	Pattern string `json:"pattern"`
+}
+
+// DomainResponse shows the DNS record that proves ownership of the domain
+// next to the domain itself.
+type DomainResponse struct {
+	ID           int                          `json:"id"`
+	Host         string                       `json:"host"`
+	Verified     bool                         `json:"verified"`
+	VerifiedAt   *time.Time                   `json:"verified_at"`
+	Verification VerificationRecord           `json:"verification"`
+	ErrorPages   *repository.DomainErrorPages `json:"error_pages"`
+	CreatedAt    time.Time                    `json:"created_at"`
+}
+
+// SetErrorPagesRequest replaces the domain's error pages; null restores the
+// defaults.
+type SetErrorPagesRequest struct {
+	ErrorPages *repository.DomainErrorPages `json:"error_pages"`
+}
+
+type VerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
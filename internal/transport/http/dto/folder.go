@@ -0,0 +1,10 @@
+package dto
+
+type FolderRequest struct {
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type MoveLinkRequest struct {
+	FolderID *int `json:"folder_id"`
+}
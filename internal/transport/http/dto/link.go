@@ -0,0 +1,200 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+)
+
+type CreateLinkRequest struct {
+	URL         string   `json:"url"`
+	Alias       string   `json:"alias,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	FolderID    *int     `json:"folder_id,omitempty"`
+	DomainID    *int     `json:"domain_id,omitempty"`
+
+	Interstitial bool `json:"interstitial,omitempty"`
+
+	UTMSource        string `json:"utm_source,omitempty"`
+	UTMMedium        string `json:"utm_medium,omitempty"`
+	UTMCampaign      string `json:"utm_campaign,omitempty"`
+	UTMTerm          string `json:"utm_term,omitempty"`
+	UTMContent       string `json:"utm_content,omitempty"`
+	QueryPassthrough bool   `json:"query_passthrough,omitempty"`
+}
+
+type BatchCreateLinksRequest struct {
+	Links []CreateLinkRequest `json:"links"`
+}
+
+type BatchLinkResult struct {
+	Index    int              `json:"index"`
+	Link     *repository.Link `json:"link,omitempty"`
+	Existing bool             `json:"existing"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type BatchCreateLinksResponse struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchLinkResult `json:"results"`
+}
+
+type ImportLinkResult struct {
+	Row           int              `json:"row"`
+	Status        string           `json:"status"`
+	Link          *repository.Link `json:"link,omitempty"`
+	CodePreserved bool             `json:"code_preserved"`
+	Error         string           `json:"error,omitempty"`
+}
+
+type ImportLinksResponse struct {
+	Created  int                `json:"created"`
+	Existing int                `json:"existing"`
+	Failed   int                `json:"failed"`
+	Results  []ImportLinkResult `json:"results"`
+}
+
+type LinkListResponse struct {
+	Links      []*repository.Link `json:"links"`
+	Total      int                `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type LinkSearchResult struct {
+	Link       *repository.Link  `json:"link"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type LinkSearchResponse struct {
+	Results []LinkSearchResult `json:"results"`
+}
+
+type SetTargetingRulesRequest struct {
+	Rules []repository.TargetingRule `json:"rules"`
+}
+
+// SetScheduleRequest replaces the link's schedule; a null schedule removes it.
+type SetScheduleRequest struct {
+	Schedule *repository.LinkSchedule `json:"schedule"`
+}
+
+// SetDeepLinkRequest replaces the link's app targets; null removes them.
+type SetDeepLinkRequest struct {
+	DeepLink *repository.DeepLink `json:"deep_link"`
+}
+
+type SetVariantsRequest struct {
+	Variants []repository.LinkVariant `json:"variants"`
+}
+
+// UpdateLinkRequest is a JSON Merge Patch (RFC 7396) for a link. Only the
+// fields listed here can be changed; null clears an optional field.
+type UpdateLinkRequest struct {
+	OriginalURL *string `json:"original_url"`
+	ShortCode   *string `json:"short_code" validate:"omitnil,min=3,max=32"`
+	Title       *string `json:"title" validate:"omitnil,max=255"`
+	Description *string `json:"description" validate:"omitnil,max=1000"`
+	Notes       *string `json:"notes" validate:"omitnil,max=5000"`
+
+	Interstitial *bool `json:"interstitial"`
+
+	UTMSource        *string `json:"utm_source" validate:"omitnil,max=255"`
+	UTMMedium        *string `json:"utm_medium" validate:"omitnil,max=255"`
+	UTMCampaign      *string `json:"utm_campaign" validate:"omitnil,max=255"`
+	UTMTerm          *string `json:"utm_term" validate:"omitnil,max=255"`
+	UTMContent       *string `json:"utm_content" validate:"omitnil,max=255"`
+	QueryPassthrough *bool   `json:"query_passthrough"`
+}
+
+// clearableLinkFields maps each updatable field to whether it may be set to
+// null.
+var clearableLinkFields = map[string]bool{
+	"original_url": false,
+	"short_code":   false,
+	"title":        true,
+	"description":  true,
+	"notes":        true,
+	"interstitial": false,
+
+	"utm_source":        true,
+	"utm_medium":        true,
+	"utm_campaign":      true,
+	"utm_term":          true,
+	"utm_content":       true,
+	"query_passthrough": false,
+}
+
+func (r *UpdateLinkRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields == nil {
+		return errors.New("patch must be a JSON object")
+	}
+
+	var cleared []string
+	for name, value := range fields {
+		clearable, ok := clearableLinkFields[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		if string(value) != "null" {
+			continue
+		}
+		if !clearable {
+			return fmt.Errorf("field %q cannot be null", name)
+		}
+		cleared = append(cleared, name)
+	}
+
+	type plain UpdateLinkRequest
+	var patch plain
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return err
+	}
+
+	clearable := map[string]**string{
+		"title":        &patch.Title,
+		"description":  &patch.Description,
+		"notes":        &patch.Notes,
+		"utm_source":   &patch.UTMSource,
+		"utm_medium":   &patch.UTMMedium,
+		"utm_campaign": &patch.UTMCampaign,
+		"utm_term":     &patch.UTMTerm,
+		"utm_content":  &patch.UTMContent,
+	}
+	for _, name := range cleared {
+		empty := ""
+		*clearable[name] = &empty
+	}
+
+	*r = UpdateLinkRequest(patch)
+	return nil
+}
+
+func (r UpdateLinkRequest) LinkUpdate() repository.LinkUpdate {
+	return repository.LinkUpdate{
+		OriginalURL: r.OriginalURL,
+		ShortCode:   r.ShortCode,
+		Title:       r.Title,
+		Description: r.Description,
+		Notes:       r.Notes,
+
+		Interstitial: r.Interstitial,
+
+		UTMSource:        r.UTMSource,
+		UTMMedium:        r.UTMMedium,
+		UTMCampaign:      r.UTMCampaign,
+		UTMTerm:          r.UTMTerm,
+		UTMContent:       r.UTMContent,
+		QueryPassthrough: r.QueryPassthrough,
+	}
+}
@@ -0,0 +1,9 @@
+package dto
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type SetLinkTagsRequest struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandlerInterface interface {
+	DomainRules(w http.ResponseWriter, r *http.Request)
+	StoreDomainRule(w http.ResponseWriter, r *http.Request)
+	DestroyDomainRule(w http.ResponseWriter, r *http.Request)
+	AdminMiddleware(next http.Handler) http.Handler
+}
+
+// AdminHandler serves the endpoints that change service-wide settings. Only
+// the users listed in ADMIN_USER_IDS may call them.
+type AdminHandler struct {
+	domainRuleService service.DomainRuleServiceInterface
+	admins            map[int]bool
+}
+
+func NewAdminHandler(domainRuleService service.DomainRuleServiceInterface, adminIDs []int) *AdminHandler {
+	admins := make(map[int]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
+	return &AdminHandler{
+		domainRuleService: domainRuleService,
+		admins:            admins,
+	}
+}
+
+// AdminMiddleware runs after AuthMiddleware and rejects users who are not
+// admins.
+func (h *AdminHandler) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value("userID").(int)
+		if !h.admins[userID] {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *AdminHandler) DomainRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.domainRuleService.GetRules()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (h *AdminHandler) StoreDomainRule(w http.ResponseWriter, r *http.Request) {
+	var input dto.DomainRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.domainRuleService.AddRule(input.Action, input.Pattern)
+	if err != nil {
+		writeDomainRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *AdminHandler) DestroyDomainRule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err := h.domainRuleService.DeleteRule(id); err != nil {
+		writeDomainRuleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDomainRuleError(w http.ResponseWriter, err error) {
+	var invalid service.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repository.ErrDomainRuleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrDomainRuleExists):
+		status = http.StatusConflict
+	case errors.As(err, &invalid):
+		status = http.StatusBadRequest
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type AppLinksHandlerInterface interface {
+	AppleAppSiteAssociation(w http.ResponseWriter, r *http.Request)
+	AssetLinks(w http.ResponseWriter, r *http.Request)
+}
+
+// AppLinksHandler serves the files that let mobile apps claim short links,
+// so that iOS and Android open them in the app without going through the
+// browser. Both files are generated from the configured app identifiers.
+type AppLinksHandler struct {
+	appleAppIDs         []string
+	androidPackage      string
+	androidFingerprints []string
+}
+
+// NewAppLinksHandler creates the handler. appleAppIDs are "TEAMID.bundle.id"
+// values and androidFingerprints the SHA-256 fingerprints of the app's
+// signing certificates.
+func NewAppLinksHandler(appleAppIDs []string, androidPackage string, androidFingerprints []string) *AppLinksHandler {
+	return &AppLinksHandler{
+		appleAppIDs:         appleAppIDs,
+		androidPackage:      androidPackage,
+		androidFingerprints: androidFingerprints,
+	}
+}
+
+// AppleAppSiteAssociation lets the iOS apps handle every short link, leaving
+// the API and QR code images to the browser.
+func (h *AppLinksHandler) AppleAppSiteAssociation(w http.ResponseWriter, r *http.Request) {
+	if len(h.appleAppIDs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeAppLinksFile(w, map[string]interface{}{
+		"applinks": map[string]interface{}{
+			"details": []map[string]interface{}{{
+				"appIDs": h.appleAppIDs,
+				"components": []map[string]interface{}{
+					{"/": "/api/*", "exclude": true},
+					{"/": "/*/qr", "exclude": true},
+					{"/": "/*"},
+				},
+			}},
+		},
+	})
+}
+
+// AssetLinks lets the Android app handle every short link.
+func (h *AppLinksHandler) AssetLinks(w http.ResponseWriter, r *http.Request) {
+	if h.androidPackage == "" || len(h.androidFingerprints) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeAppLinksFile(w, []map[string]interface{}{{
+		"relation": []string{"delegate_permission/common.handle_all_urls"},
+		"target": map[string]interface{}{
+			"namespace":                "android_app",
+			"package_name":             h.androidPackage,
+			"sha256_cert_fingerprints": h.androidFingerprints,
+		},
+	}})
+}
+
+func writeAppLinksFile(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+	"github.com/go-chi/chi/v5"
+)
+
+type DomainHandlerInterface interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Store(w http.ResponseWriter, r *http.Request)
+	Verify(w http.ResponseWriter, r *http.Request)
+	Destroy(w http.ResponseWriter, r *http.Request)
+	SetErrorPages(w http.ResponseWriter, r *http.Request)
+}
+
+type DomainHandler struct {
+	domainService service.DomainServiceInterface
+}
+
+func NewDomainHandler(domainService service.DomainServiceInterface) *DomainHandler {
+	return &DomainHandler{domainService: domainService}
+}
+
+func (h *DomainHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	domains, err := h.domainService.GetDomains(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]dto.DomainResponse, len(domains))
+	for i, domain := range domains {
+		response[i] = domainResponse(domain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Store registers the domain. The response contains the TXT record that has
+// to be published before calling Verify.
+func (h *DomainHandler) Store(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	var input dto.DomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	domain, err := h.domainService.AddDomain(userID, input.Host)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domainResponse(domain))
+}
+
+func (h *DomainHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	domain, err := h.domainService.VerifyDomain(id, userID)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domainResponse(domain))
+}
+
+func (h *DomainHandler) Destroy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err := h.domainService.DeleteDomain(id, userID); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DomainHandler) SetErrorPages(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetErrorPagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	domain, err := h.domainService.SetErrorPages(id, userID, input.ErrorPages)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domainResponse(domain))
+}
+
+func domainResponse(domain *repository.Domain) dto.DomainResponse {
+	return dto.DomainResponse{
+		ID:         domain.ID,
+		Host:       domain.Host,
+		Verified:   domain.VerifiedAt != nil,
+		VerifiedAt: domain.VerifiedAt,
+		Verification: dto.VerificationRecord{
+			Type:  "TXT",
+			Name:  service.DomainVerificationPrefix + domain.Host,
+			Value: service.DomainVerificationValue + domain.VerificationToken,
+		},
+		ErrorPages: domain.ErrorPages,
+		CreatedAt:  domain.CreatedAt,
+	}
+}
+
+func writeDomainError(w http.ResponseWriter, err error) {
+	var invalid service.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repository.ErrDomainNotFound):
+		status = http.StatusNotFound
+	case errors.As(err, &invalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, repository.ErrDomainExists), errors.Is(err, service.ErrDomainHasLinks):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrVerificationRecordNotFound):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrVerificationLookup):
+		status = http.StatusBadGateway
+	case errors.Is(err, service.ErrDomainBlocked), errors.Is(err, service.ErrDomainNotAllowed),
+		errors.Is(err, service.ErrURLFlagged):
+		status = http.StatusUnprocessableEntity
+	}
+	http.Error(w, err.Error(), status)
+}
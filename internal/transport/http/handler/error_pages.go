@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+)
+
+// defaultErrorPages are shown when neither the domain nor the configured
+// fallback URL says otherwise.
+var defaultErrorPages = map[string]struct {
+	status  int
+	title   string
+	message string
+}{
+	repository.ErrorPageNotFound: {http.StatusNotFound, "Link not found",
+		"This short link does not exist. Check that it was copied completely."},
+	repository.ErrorPageExpired: {http.StatusGone, "Link has expired",
+		"This short link is no longer active."},
+	repository.ErrorPageDisabled: {http.StatusGone, "Link is disabled",
+		"This short link has been disabled."},
+	repository.ErrorPageBlocked: {http.StatusGone, "Link is blocked",
+		"This short link points to a site that is not allowed."},
+}
+
+// ErrorPages answers visits that cannot be redirected. A custom domain can
+// replace each page or send visitors elsewhere; on other hosts visitors are
+// sent to fallbackURL when it is set.
+type ErrorPages struct {
+	domainService service.DomainServiceInterface
+	fallbackURL   string
+}
+
+func NewErrorPages(domainService service.DomainServiceInterface, fallbackURL string) *ErrorPages {
+	return &ErrorPages{domainService: domainService, fallbackURL: fallbackURL}
+}
+
+// Render writes the error page of the kind for the request's host.
+func (p *ErrorPages) Render(w http.ResponseWriter, r *http.Request, kind string) {
+	page := defaultErrorPages[kind]
+	title, message, redirectURL := page.title, page.message, p.fallbackURL
+
+	domainPages, err := p.domainService.ErrorPages(r.Host)
+	if err != nil {
+		log.Printf("Failed to load error pages for %s: %v", r.Host, err)
+	}
+	if domainPages != nil {
+		redirectURL = domainPages.FallbackURL
+		if custom := domainPages.Page(kind); custom != nil {
+			redirectURL = custom.RedirectURL
+			if custom.Title != "" {
+				title = custom.Title
+			}
+			if custom.Message != "" {
+				message = custom.Message
+			}
+		}
+	}
+
+	if redirectURL != "" {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, redirectURL, http.StatusFound)
+		return
+	}
+
+	renderPage(w, page.status, "error.html", map[string]interface{}{
+		"Title":   title,
+		"Message": message,
+		"Status":  http.StatusText(page.status),
+	})
+}
+
+// errorPageKind maps the redirect service's errors to error page kinds. It
+// returns an empty string for errors that are not the visitor's to see, such
+// as database failures.
+func errorPageKind(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrLinkNotFound), errors.Is(err, service.ErrLinkNotActive):
+		return repository.ErrorPageNotFound
+	case errors.Is(err, service.ErrLinkExpired):
+		return repository.ErrorPageExpired
+	case errors.Is(err, service.ErrLinkDisabled):
+		return repository.ErrorPageDisabled
+	case errors.Is(err, service.ErrLinkBlocked):
+		return repository.ErrorPageBlocked
+	}
+	return ""
+}
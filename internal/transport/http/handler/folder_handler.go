@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+	"github.com/go-chi/chi/v5"
+)
+
+type FolderHandlerInterface interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Store(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Destroy(w http.ResponseWriter, r *http.Request)
+}
+
+type FolderHandler struct {
+	folderService service.FolderServiceInterface
+}
+
+func NewFolderHandler(folderService service.FolderServiceInterface) *FolderHandler {
+	return &FolderHandler{folderService: folderService}
+}
+
+func (h *FolderHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	folders, err := h.folderService.GetFolders(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(folders)
+}
+
+func (h *FolderHandler) Store(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	var input dto.FolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(userID, input.Name, input.ParentID)
+	if err != nil {
+		writeFolderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(folder)
+}
+
+func (h *FolderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.FolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	folder, err := h.folderService.UpdateFolder(id, userID, input.Name, input.ParentID)
+	if err != nil {
+		writeFolderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(folder)
+}
+
+func (h *FolderHandler) Destroy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err := h.folderService.DeleteFolder(id, userID); err != nil {
+		writeFolderError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeFolderError(w http.ResponseWriter, err error) {
+	var invalid service.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repository.ErrFolderNotFound):
+		status = http.StatusNotFound
+	case errors.As(err, &invalid):
+		status = http.StatusBadRequest
+	}
+	http.Error(w, err.Error(), status)
+}
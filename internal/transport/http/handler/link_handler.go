@@ -2,65 +2,180 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
 	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+	"github.com/RamanDudoits/shortLink-go/pkg/validator"
 	"github.com/go-chi/chi/v5"
 )
 
 type LinkHandlerInterface interface {
 	List(w http.ResponseWriter, r *http.Request)
+	Search(w http.ResponseWriter, r *http.Request)
 	Store(w http.ResponseWriter, r *http.Request)
+	StoreBatch(w http.ResponseWriter, r *http.Request)
+	Import(w http.ResponseWriter, r *http.Request)
+	Export(w http.ResponseWriter, r *http.Request)
 	Destroy(w http.ResponseWriter, r *http.Request)
 	Get(w http.ResponseWriter, r *http.Request)
 	Update(w http.ResponseWriter, r *http.Request)
+	Versions(w http.ResponseWriter, r *http.Request)
+	Revert(w http.ResponseWriter, r *http.Request)
+	Trash(w http.ResponseWriter, r *http.Request)
+	Restore(w http.ResponseWriter, r *http.Request)
+	SetTags(w http.ResponseWriter, r *http.Request)
+	Move(w http.ResponseWriter, r *http.Request)
+	SetTargeting(w http.ResponseWriter, r *http.Request)
+	GeoStats(w http.ResponseWriter, r *http.Request)
+	VisitorStats(w http.ResponseWriter, r *http.Request)
+	SetVariants(w http.ResponseWriter, r *http.Request)
+	SetSchedule(w http.ResponseWriter, r *http.Request)
+	SetDeepLink(w http.ResponseWriter, r *http.Request)
+	VariantStats(w http.ResponseWriter, r *http.Request)
 	Redirect(w http.ResponseWriter, r *http.Request)
 }
 
 type LinkHandler struct {
-	linkService service.LinkServiceInterface
+	linkService         service.LinkServiceInterface
 	linkredirectService service.LinkServiceRedirectInterface
+	validator           *validator.Validator
+	clientIPs           *ClientIPResolver
+	errorPages          *ErrorPages
+	proceedTokens       *service.ProceedTokens
 }
 
 func NewLinkHandler(
 	linkService service.LinkServiceInterface,
-	linkredirectService service.LinkServiceRedirectInterface,) *LinkHandler {
+	linkredirectService service.LinkServiceRedirectInterface,
+	validator *validator.Validator,
+	clientIPs *ClientIPResolver,
+	errorPages *ErrorPages,
+	proceedTokens *service.ProceedTokens) *LinkHandler {
 	return &LinkHandler{
-		linkService: linkService,
+		linkService:         linkService,
 		linkredirectService: linkredirectService,
+		validator:           validator,
+		clientIPs:           clientIPs,
+		errorPages:          errorPages,
+		proceedTokens:       proceedTokens,
 	}
 }
 
 func (h *LinkHandler) List(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
-	
-	links, err := h.linkService.GetUserLinks(userID)
+	query := r.URL.Query()
+
+	params := service.LinkListParams{
+		Sort:            query.Get("sort"),
+		Order:           query.Get("order"),
+		DestinationHost: query.Get("destination_host"),
+		Tag:             query.Get("tag"),
+		Cursor:          query.Get("cursor"),
+	}
+
+	if domain := query.Get("domain_id"); domain != "" {
+		domainID, err := strconv.Atoi(domain)
+		if err != nil {
+			http.Error(w, "Invalid domain_id", http.StatusBadRequest)
+			return
+		}
+		params.DomainID = &domainID
+	}
+
+	if folder := query.Get("folder_id"); folder != "" {
+		folderID, err := strconv.Atoi(folder)
+		if err != nil {
+			http.Error(w, "Invalid folder_id", http.StatusBadRequest)
+			return
+		}
+		params.FolderID = &folderID
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		params.Limit = value
+	}
+
+	var err error
+	if params.CreatedFrom, err = parseDateParam(query.Get("created_from"), false); err != nil {
+		http.Error(w, "Invalid created_from", http.StatusBadRequest)
+		return
+	}
+	if params.CreatedTo, err = parseDateParam(query.Get("created_to"), true); err != nil {
+		http.Error(w, "Invalid created_to", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.linkService.ListLinks(userID, params)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeLinkError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(links)
+	json.NewEncoder(w).Encode(dto.LinkListResponse{
+		Links:      list.Links,
+		Total:      list.Total,
+		NextCursor: list.NextCursor,
+	})
 }
 
-func (h *LinkHandler) Store(w http.ResponseWriter, r *http.Request) {
+func (h *LinkHandler) Search(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
-	
-	var input struct {
-		URL string `json:"url"`
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	hits, err := h.linkService.SearchLinks(userID, query.Get("q"), limit)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	response := dto.LinkSearchResponse{Results: make([]dto.LinkSearchResult, len(hits))}
+	for i, hit := range hits {
+		response.Results[i] = dto.LinkSearchResult{
+			Link:       hit.Link,
+			Rank:       hit.Rank,
+			Highlights: hit.Highlights,
+		}
 	}
-	
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *LinkHandler) Store(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	var input dto.CreateLinkRequest
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	link, err := h.linkService.Create(input.URL, userID)
+	link, err := h.linkService.Create(createLinkInput(input), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeLinkError(w, err)
 		return
 	}
 
@@ -69,12 +184,49 @@ func (h *LinkHandler) Store(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(link)
 }
 
+func (h *LinkHandler) StoreBatch(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	var input dto.BatchCreateLinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	items := make([]service.CreateLinkInput, len(input.Links))
+	for i, l := range input.Links {
+		items[i] = createLinkInput(l)
+	}
+
+	results, err := h.linkService.CreateBatch(items, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := dto.BatchCreateLinksResponse{
+		Results: make([]dto.BatchLinkResult, len(results)),
+	}
+	for i, result := range results {
+		response.Results[i] = dto.BatchLinkResult{Index: i, Link: result.Link, Existing: result.Existing}
+		if result.Err != nil {
+			response.Results[i].Error = result.Err.Error()
+			response.Failed++
+			continue
+		}
+		response.Succeeded++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func (h *LinkHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	
+
 	if err := h.linkService.DeleteLink(id, userID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeLinkError(w, err)
 		return
 	}
 
@@ -84,16 +236,10 @@ func (h *LinkHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 func (h *LinkHandler) Get(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	
+
 	link, err := h.linkService.GetLink(id, userID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "access denied" {
-			status = http.StatusForbidden
-		} else if err.Error() == "link not found" {
-			status = http.StatusNotFound
-		}
-		http.Error(w, err.Error(), status)
+		writeLinkError(w, err)
 		return
 	}
 
@@ -104,34 +250,483 @@ func (h *LinkHandler) Get(w http.ResponseWriter, r *http.Request) {
 func (h *LinkHandler) Update(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value("userID").(int)
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
-	
-	var updates map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+
+	var req dto.UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.UpdateLink(id, userID, req.LinkUpdate())
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *LinkHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	versions, err := h.linkService.GetLinkVersions(id, userID)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+func (h *LinkHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.RevertLink(id, userID, version)
+	if err != nil {
+		writeLinkError(w, err)
 		return
 	}
 
-	link, err := h.linkService.UpdateLink(id, userID, updates)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *LinkHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	links, err := h.linkService.GetDeletedLinks(userID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+func (h *LinkHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err := h.linkService.RestoreLink(id, userID); err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LinkHandler) SetTags(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetLinkTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.SetLinkTags(id, userID, input.Tags)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(link)
 }
 
+func (h *LinkHandler) Move(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.MoveLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.MoveLink(id, userID, input.FolderID)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *LinkHandler) SetTargeting(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetTargetingRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.SetTargetingRules(id, userID, input.Rules)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+// GeoStats splits the link's clicks by country and region. The optional
+// from and to parameters limit the period, like created_from and created_to
+// in List.
+func (h *LinkHandler) GeoStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	query := r.URL.Query()
+
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.linkService.GetGeoStats(id, userID, from, to, query.Get("include_bots") == "1")
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *LinkHandler) VisitorStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	query := r.URL.Query()
+
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.linkService.GetVisitorStats(id, userID, from, to)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *LinkHandler) SetVariants(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetVariantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.SetVariants(id, userID, input.Variants)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *LinkHandler) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.SetSchedule(id, userID, input.Schedule)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+func (h *LinkHandler) SetDeepLink(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.SetDeepLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.linkService.SetDeepLink(id, userID, input.DeepLink)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+// proceedURL returns the link to continue past the preview or warning page
+// to the destination shown on it. It carries a signed proceed token, is
+// relative, so the visitor stays on the host they came from, and keeps the
+// query string, which passthrough links forward to the destination.
+func (h *LinkHandler) proceedURL(link *repository.Link, destination string, r *http.Request) string {
+	query := r.URL.Query()
+	query.Set("proceed", h.proceedTokens.Mint(link.ID, destination))
+	return "/" + link.ShortCode + "?" + query.Encode()
+}
+
+// VariantStats reports the link's clicks per A/B variant for the period given
+// by from and to.
+func (h *LinkHandler) VariantStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	query := r.URL.Query()
+
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		http.Error(w, "Invalid from", http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		http.Error(w, "Invalid to", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.linkService.GetVariantStats(id, userID, from, to, query.Get("include_bots") == "1")
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"variants": stats})
+}
+
+// Redirect sends the visitor to the link's destination.
+//
+// A short code ending in "+" shows the preview page instead, and so do links
+// with the interstitial enabled until the visitor continues with a valid
+// proceed token. If
+// the visitor's destination was flagged by the threat scanner, a warning is
+// shown first.
+//
+// Visitors of links with A/B variants keep their variant through a cookie.
+// Mobile visitors of links with an app URL get a page that tries to open the
+// app before falling back to the website.
+//
+// Unknown, disabled and blocked links, and links outside their scheduled
+// window, show an error page.
 func (h *LinkHandler) Redirect(w http.ResponseWriter, r *http.Request) {
 	shortLink := chi.URLParam(r, "shortLink")
-	
-	link, err := h.linkredirectService.GetByShortCode(shortLink)
+	preview := strings.HasSuffix(shortLink, "+")
+
+	link, err := h.linkredirectService.GetByShortCode(r.Host, strings.TrimSuffix(shortLink, "+"))
+	if err == nil {
+		err = h.linkredirectService.CheckSchedule(link)
+	}
 	if err != nil {
-		http.Error(w, "Link not found", http.StatusNotFound)
+		kind := errorPageKind(err)
+		if kind == "" {
+			log.Printf("Failed to resolve short link %q on %s: %v", shortLink, r.Host, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.errorPages.Render(w, r, kind)
+		return
+	}
+
+	source := repository.ClickSourceDirect
+	if r.URL.Query().Get("src") == repository.ClickSourceQR {
+		source = repository.ClickSourceQR
+	}
+	visit := service.Visit{
+		Query:     r.URL.Query(),
+		UserAgent: r.UserAgent(),
+		IP:        h.clientIPs.ClientIP(r),
+		Method:    r.Method,
+		Header:    r.Header,
+	}
+	if cookie, err := r.Cookie(variantCookie); err == nil {
+		visit.Variant = cookie.Value
+	}
+	visit.Variant = h.linkredirectService.ChooseVariant(link, visit)
+	if visit.Variant != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     variantCookie,
+			Value:    visit.Variant,
+			Path:     "/" + link.ShortCode,
+			MaxAge:   int(variantCookieMaxAge.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	// The pages below show, and warn about, the destination this visitor
+	// would be sent to, which may differ from the link's own URL.
+	destination := h.linkredirectService.ResolveDestination(link, visit)
+	threat := h.linkredirectService.DestinationThreat(link, visit)
+
+	// Only a token minted for this link and destination skips the interstitial
+	// or the warning.
+	token := r.URL.Query().Get("proceed")
+	proceed := token != "" && h.proceedTokens.Verify(token, link.ID, destination)
+	proceedURL := h.proceedURL(link, destination, r)
+
+	switch {
+	case preview || (link.Interstitial && !proceed && threat == ""):
+		renderPage(w, http.StatusOK, "preview.html", map[string]interface{}{
+			"Title":       link.Title,
+			"Description": link.Description,
+			"ShortCode":   link.ShortCode,
+			"URL":         destination,
+			"CreatedAt":   link.CreatedAt,
+			"Clicks":      link.ClickCount,
+			"Threat":      threatLabel(threat),
+			"ProceedURL":  proceedURL,
+		})
+		return
+	case threat != "" && !proceed:
+		renderPage(w, http.StatusOK, "warning.html", map[string]string{
+			"Threat":     threatLabel(threat),
+			"URL":        destination,
+			"ProceedURL": proceedURL,
+		})
 		return
 	}
-	if err := h.linkredirectService.IncrementClickCount(link.ID, link.ClickCount); err != nil {
-		log.Printf("Failed to increment click count for link %d: %v", link.ID, err)
+
+	if err := h.linkredirectService.RecordClick(link, source, visit); err != nil {
+		log.Printf("Failed to record click for link %d: %v", link.ID, err)
+	}
+
+	// Browsers cache permanent redirects, which would pin a visitor to the
+	// first destination they got.
+	status := http.StatusMovedPermanently
+	if len(link.TargetingRules) > 0 || len(link.Variants) > 0 || link.Schedule != nil || link.DeepLink != nil {
+		status = http.StatusFound
 	}
 
-	http.Redirect(w, r, link.OriginalURL, http.StatusMovedPermanently)
-}
\ No newline at end of file
+	if appURL := h.linkredirectService.AppURL(link, visit); appURL != "" {
+		// The app URL has been checked against unsafe schemes already, and
+		// html/template would otherwise refuse a custom scheme in href.
+		renderPage(w, http.StatusOK, "bridge.html", map[string]interface{}{
+			"AppURL": template.URL(appURL),
+			"URL":    destination,
+		})
+		return
+	}
+	http.Redirect(w, r, destination, status)
+}
+
+// variantCookie remembers the visitor's A/B variant. It is scoped to the
+// short link's path, so every link keeps its own choice.
+const (
+	variantCookie       = "sl_variant"
+	variantCookieMaxAge = 90 * 24 * time.Hour
+)
+
+func threatLabel(threatType string) string {
+	return strings.ReplaceAll(threatType, "_", " ")
+}
+
+func writeLinkError(w http.ResponseWriter, err error) {
+	var invalid service.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, repository.ErrLinkNotFound), errors.Is(err, repository.ErrVersionNotFound),
+		errors.Is(err, repository.ErrFolderNotFound), errors.Is(err, repository.ErrDomainNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrShortCodeTaken), errors.Is(err, service.ErrDomainNotVerified):
+		status = http.StatusConflict
+	case errors.As(err, &invalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrDomainBlocked), errors.Is(err, service.ErrDomainNotAllowed):
+		status = http.StatusUnprocessableEntity
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain date
+// used as an upper bound covers the whole day.
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func createLinkInput(req dto.CreateLinkRequest) service.CreateLinkInput {
+	return service.CreateLinkInput{
+		URL:         req.URL,
+		Alias:       req.Alias,
+		Title:       req.Title,
+		Description: req.Description,
+		Notes:       req.Notes,
+		Tags:        req.Tags,
+		FolderID:    req.FolderID,
+		DomainID:    req.DomainID,
+
+		Interstitial: req.Interstitial,
+
+		UTMSource:        req.UTMSource,
+		UTMMedium:        req.UTMMedium,
+		UTMCampaign:      req.UTMCampaign,
+		UTMTerm:          req.UTMTerm,
+		UTMContent:       req.UTMContent,
+		QueryPassthrough: req.QueryPassthrough,
+	}
+}
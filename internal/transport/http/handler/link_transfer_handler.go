@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	maxImportBodySize = 10 << 20
+)
+
+var exportCSVHeader = []string{
+	"id", "original_url", "short_code", "title", "description", "notes", "tags", "click_count", "created_at",
+}
+
+// Column names accepted on import, including the ones used by Bitly exports.
+var (
+	importURLColumns         = []string{"original_url", "url", "long_url", "destination"}
+	importShortCodeColumns   = []string{"short_code", "alias", "short_link", "custom_back_half", "link", "short_url"}
+	importTitleColumns       = []string{"title", "name"}
+	importDescriptionColumns = []string{"description"}
+	importNotesColumns       = []string{"notes"}
+	importTagsColumns        = []string{"tags"}
+)
+
+func (h *LinkHandler) Export(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatCSV
+	}
+
+	var writeLink func(*repository.Link) error
+	var flush func() error
+
+	switch format {
+	case formatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportCSVHeader); err != nil {
+			return
+		}
+		writeLink = func(link *repository.Link) error {
+			return cw.Write([]string{
+				strconv.Itoa(link.ID),
+				csvCell(link.OriginalURL),
+				csvCell(link.ShortCode),
+				csvCell(link.Title),
+				csvCell(link.Description),
+				csvCell(link.Notes),
+				csvCell(strings.Join(link.Tags, ",")),
+				strconv.Itoa(link.ClickCount),
+				link.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case formatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		writeLink = func(link *repository.Link) error {
+			return enc.Encode(link)
+		}
+		flush = func() error { return nil }
+	default:
+		http.Error(w, "Unsupported format", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="links.%s"`, format))
+
+	if err := h.linkService.ExportLinks(userID, writeLink); err != nil {
+		log.Printf("Failed to export links for user %d: %v", userID, err)
+	}
+	if err := flush(); err != nil {
+		log.Printf("Failed to flush export for user %d: %v", userID, err)
+	}
+}
+
+func (h *LinkHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	var items []service.ImportLinkInput
+	var parseErrors []dto.ImportLinkResult
+	var err error
+
+	switch importFormat(r) {
+	case formatCSV:
+		items, parseErrors, err = parseCSVImport(body)
+	case formatNDJSON:
+		items, parseErrors, err = parseNDJSONImport(body)
+	default:
+		http.Error(w, "Unsupported format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := dto.ImportLinksResponse{Results: parseErrors}
+	response.Failed = len(parseErrors)
+
+	if len(items) > 0 {
+		results, err := h.linkService.ImportLinks(items, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, result := range results {
+			row := dto.ImportLinkResult{
+				Row:           result.Row,
+				Status:        result.Status,
+				Link:          result.Link,
+				CodePreserved: result.CodePreserved,
+			}
+			if result.Err != nil {
+				row.Error = result.Err.Error()
+			}
+
+			switch result.Status {
+			case service.ImportStatusCreated:
+				response.Created++
+			case service.ImportStatusExisting:
+				response.Existing++
+			default:
+				response.Failed++
+			}
+			response.Results = append(response.Results, row)
+		}
+	}
+
+	sort.Slice(response.Results, func(i, j int) bool {
+		return response.Results[i].Row < response.Results[j].Row
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/x-ndjson"),
+		strings.HasPrefix(contentType, "application/jsonl"):
+		return formatNDJSON
+	default:
+		return formatCSV
+	}
+}
+
+func parseCSVImport(body io.Reader) ([]service.ImportLinkInput, []dto.ImportLinkResult, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("missing CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	urlColumn := findColumn(columns, importURLColumns)
+	if urlColumn < 0 {
+		return nil, nil, errors.New("missing url column")
+	}
+	codeColumn := findColumn(columns, importShortCodeColumns)
+	titleColumn := findColumn(columns, importTitleColumns)
+	descriptionColumn := findColumn(columns, importDescriptionColumns)
+	notesColumn := findColumn(columns, importNotesColumns)
+	tagsColumn := findColumn(columns, importTagsColumns)
+
+	var items []service.ImportLinkInput
+	var parseErrors []dto.ImportLinkResult
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			parseErrors = append(parseErrors, dto.ImportLinkResult{
+				Row:    row,
+				Status: service.ImportStatusFailed,
+				Error:  parseErr.Err.Error(),
+			})
+			continue
+		}
+
+		item := service.ImportLinkInput{
+			Row:         row,
+			URL:         field(record, urlColumn),
+			ShortCode:   importedShortCode(field(record, codeColumn)),
+			Title:       field(record, titleColumn),
+			Description: field(record, descriptionColumn),
+			Notes:       field(record, notesColumn),
+		}
+		if tags := field(record, tagsColumn); tags != "" {
+			item.Tags = strings.Split(tags, ",")
+		}
+		items = append(items, item)
+	}
+
+	return items, parseErrors, nil
+}
+
+func parseNDJSONImport(body io.Reader) ([]service.ImportLinkInput, []dto.ImportLinkResult, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var items []service.ImportLinkInput
+	var parseErrors []dto.ImportLinkResult
+	row := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		row++
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			parseErrors = append(parseErrors, dto.ImportLinkResult{
+				Row:    row,
+				Status: service.ImportStatusFailed,
+				Error:  "invalid JSON",
+			})
+			continue
+		}
+
+		items = append(items, service.ImportLinkInput{
+			Row:         row,
+			URL:         stringField(record, importURLColumns),
+			ShortCode:   importedShortCode(stringField(record, importShortCodeColumns)),
+			Title:       stringField(record, importTitleColumns),
+			Description: stringField(record, importDescriptionColumns),
+			Notes:       stringField(record, importNotesColumns),
+			Tags:        stringsField(record, importTagsColumns),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+
+	return items, parseErrors, nil
+}
+
+func findColumn(columns map[string]int, names []string) int {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// field returns the trimmed cell, undoing the escaping added by csvCell.
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	value := strings.TrimSpace(record[i])
+	// Only the quote added by csvCell is removed; other leading quotes are
+	// part of the value.
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		value = value[1:]
+	}
+	return value
+}
+
+// csvFormulaPrefixes start cells that spreadsheets evaluate as formulas.
+const csvFormulaPrefixes = "=+-@"
+
+// csvCell prefixes text that a spreadsheet would evaluate as a formula with
+// a quote, so exported links cannot run formulas when the file is opened.
+// Leading tabs and carriage returns are quoted too, since some spreadsheets
+// skip them before looking for a formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes+"\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func stringField(record map[string]interface{}, names []string) string {
+	for _, name := range names {
+		if value, ok := record[name].(string); ok && value != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// stringsField reads a list of strings, such as the tags of an exported
+// link.
+func stringsField(record map[string]interface{}, names []string) []string {
+	for _, name := range names {
+		values, ok := record[name].([]interface{})
+		if !ok {
+			continue
+		}
+		var result []string
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// importedShortCode accepts either a bare code or a full short URL such as
+// "bit.ly/abc12" and returns the code part.
+func importedShortCode(value string) string {
+	value = strings.TrimRight(value, "/")
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		return value[i+1:]
+	}
+	return value
+}
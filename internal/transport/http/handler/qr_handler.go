@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type QRHandlerInterface interface {
+	Show(w http.ResponseWriter, r *http.Request)
+	Public(w http.ResponseWriter, r *http.Request)
+}
+
+type QRHandler struct {
+	linkService         service.LinkServiceInterface
+	linkredirectService service.LinkServiceRedirectInterface
+	qrService           *service.QRService
+	baseURL             string
+	errorPages          *ErrorPages
+}
+
+func NewQRHandler(
+	linkService service.LinkServiceInterface,
+	linkredirectService service.LinkServiceRedirectInterface,
+	qrService *service.QRService,
+	baseURL string,
+	errorPages *ErrorPages) *QRHandler {
+	return &QRHandler{
+		linkService:         linkService,
+		linkredirectService: linkredirectService,
+		qrService:           qrService,
+		baseURL:             strings.TrimSuffix(baseURL, "/"),
+		errorPages:          errorPages,
+	}
+}
+
+// Show renders the QR code of one of the user's links.
+func (h *QRHandler) Show(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	link, err := h.linkService.GetLink(id, userID)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	h.render(w, r, link, "private")
+}
+
+// Public renders the QR code of any active link by its short code.
+func (h *QRHandler) Public(w http.ResponseWriter, r *http.Request) {
+	link, err := h.linkredirectService.GetByShortCode(r.Host, chi.URLParam(r, "shortLink"))
+	if err != nil {
+		h.errorPages.Render(w, r, errorPageKind(err))
+		return
+	}
+
+	h.render(w, r, link, "public")
+}
+
+func (h *QRHandler) render(w http.ResponseWriter, r *http.Request, link *repository.Link, cacheScope string) {
+	opts, err := parseQROptions(r)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	content := shortURL(h.baseURL, link) + "?src=" + repository.ClickSourceQR
+	code, err := h.qrService.Generate(content, opts)
+	if err != nil {
+		writeLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", cacheScope+", max-age=86400")
+	w.Header().Set("ETag", code.ETag)
+	if r.Header.Get("If-None-Match") == code.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", code.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(code.Data)))
+	w.Write(code.Data)
+}
+
+func parseQROptions(r *http.Request) (service.QROptions, error) {
+	query := r.URL.Query()
+	opts := service.QROptions{
+		Format:     query.Get("format"),
+		Level:      query.Get("level"),
+		Foreground: query.Get("fg"),
+		Background: query.Get("bg"),
+	}
+
+	if size := query.Get("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil {
+			return opts, service.ValidationError("invalid qr size")
+		}
+		opts.Size = value
+	}
+
+	if margin := query.Get("margin"); margin != "" {
+		value, err := strconv.Atoi(margin)
+		if err != nil {
+			return opts, service.ValidationError("invalid qr margin")
+		}
+		opts.Margin = &value
+	}
+
+	return opts, nil
+}
+
+// shortURL returns the public URL of the link. Links on a custom domain use
+// that domain with the scheme of the base URL.
+func shortURL(baseURL string, link *repository.Link) string {
+	if link.Domain == "" {
+		return baseURL + "/" + link.ShortCode
+	}
+
+	scheme := "https"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return scheme + "://" + link.Domain + "/" + link.ShortCode
+}
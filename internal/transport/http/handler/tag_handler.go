@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RamanDudoits/shortLink-go/internal/repository"
+	"github.com/RamanDudoits/shortLink-go/internal/service"
+	"github.com/RamanDudoits/shortLink-go/internal/transport/http/dto"
+	"github.com/go-chi/chi/v5"
+)
+
+type TagHandlerInterface interface {
+	List(w http.ResponseWriter, r *http.Request)
+	Store(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Destroy(w http.ResponseWriter, r *http.Request)
+}
+
+type TagHandler struct {
+	tagService service.TagServiceInterface
+}
+
+func NewTagHandler(tagService service.TagServiceInterface) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	tags, err := h.tagService.GetTags(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *TagHandler) Store(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+
+	var input dto.TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(userID, input.Name)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	var input dto.TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(id, userID, input.Name)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) Destroy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value("userID").(int)
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err := h.tagService.DeleteTag(id, userID); err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTagError(w http.ResponseWriter, err error) {
+	var invalid service.ValidationError
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repository.ErrTagNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repository.ErrTagExists):
+		status = http.StatusConflict
+	case errors.As(err, &invalid):
+		status = http.StatusBadRequest
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -8,24 +8,40 @@ import (
 	"github.com/RamanDudoits/shortLink-go/internal/transport/http/handler"
 )
 
-func NewRouter(authHandler handler.AuthHandlerInterface, linkHandler handler.LinkHandlerInterface) *chi.Mux {
+func NewRouter(
+	authHandler handler.AuthHandlerInterface,
+	linkHandler handler.LinkHandlerInterface,
+	tagHandler handler.TagHandlerInterface,
+	folderHandler handler.FolderHandlerInterface,
+	qrHandler handler.QRHandlerInterface,
+	appLinksHandler handler.AppLinksHandlerInterface,
+	domainHandler handler.DomainHandlerInterface,
+	adminHandler handler.AdminHandlerInterface,
+) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: true,
-		MaxAge: 300,
+		MaxAge:           300,
 	}))
 
 	r.Group(func(r chi.Router) {
 		r.Post("/api/auth/login", authHandler.Login)
 		r.Post("/api/auth/register", authHandler.Register)
+		r.Get("/.well-known/apple-app-site-association", appLinksHandler.AppleAppSiteAssociation)
+		r.Get("/apple-app-site-association", appLinksHandler.AppleAppSiteAssociation)
+		r.Get("/.well-known/assetlinks.json", appLinksHandler.AssetLinks)
 		r.Get("/{shortLink}", linkHandler.Redirect)
+		// Link checkers often send HEAD; it is answered like GET and counted
+		// as a bot click.
+		r.Head("/{shortLink}", linkHandler.Redirect)
+		r.Get("/{shortLink}/qr", qrHandler.Public)
 		r.Get("/api/admin/users", authHandler.GetAllUsers)
 	})
 
@@ -33,10 +49,52 @@ func NewRouter(authHandler handler.AuthHandlerInterface, linkHandler handler.Lin
 		r.Use(authHandler.AuthMiddleware)
 
 		r.Get("/api/links", linkHandler.List)
+		r.Get("/api/links/search", linkHandler.Search)
 		r.Post("/api/links", linkHandler.Store)
-		r.Delete("/api/links/destroy", linkHandler.Destroy)
+		r.Post("/api/links/batch", linkHandler.StoreBatch)
+		r.Post("/api/links/import", linkHandler.Import)
+		r.Get("/api/links/export", linkHandler.Export)
+		r.Get("/api/links/trash", linkHandler.Trash)
+		r.Delete("/api/links/{id}", linkHandler.Destroy)
+		r.Post("/api/links/{id}/restore", linkHandler.Restore)
 		r.Get("/api/links/{id}", linkHandler.Get)
 		r.Patch("/api/links/{id}/update", linkHandler.Update)
+		r.Get("/api/links/{id}/versions", linkHandler.Versions)
+		r.Post("/api/links/{id}/versions/{version}/revert", linkHandler.Revert)
+		r.Put("/api/links/{id}/tags", linkHandler.SetTags)
+		r.Put("/api/links/{id}/folder", linkHandler.Move)
+		r.Get("/api/links/{id}/qr", qrHandler.Show)
+		r.Put("/api/links/{id}/targeting", linkHandler.SetTargeting)
+		r.Put("/api/links/{id}/variants", linkHandler.SetVariants)
+		r.Put("/api/links/{id}/schedule", linkHandler.SetSchedule)
+		r.Put("/api/links/{id}/deeplink", linkHandler.SetDeepLink)
+		r.Get("/api/links/{id}/stats/geo", linkHandler.GeoStats)
+		r.Get("/api/links/{id}/stats/visitors", linkHandler.VisitorStats)
+		r.Get("/api/links/{id}/stats/variants", linkHandler.VariantStats)
+
+		r.Get("/api/tags", tagHandler.List)
+		r.Post("/api/tags", tagHandler.Store)
+		r.Patch("/api/tags/{id}", tagHandler.Update)
+		r.Delete("/api/tags/{id}", tagHandler.Destroy)
+
+		r.Get("/api/folders", folderHandler.List)
+		r.Post("/api/folders", folderHandler.Store)
+		r.Patch("/api/folders/{id}", folderHandler.Update)
+		r.Delete("/api/folders/{id}", folderHandler.Destroy)
+
+		r.Get("/api/domains", domainHandler.List)
+		r.Post("/api/domains", domainHandler.Store)
+		r.Post("/api/domains/{id}/verify", domainHandler.Verify)
+		r.Delete("/api/domains/{id}", domainHandler.Destroy)
+		r.Put("/api/domains/{id}/error-pages", domainHandler.SetErrorPages)
+
+		r.Group(func(r chi.Router) {
+			r.Use(adminHandler.AdminMiddleware)
+
+			r.Get("/api/admin/domain-rules", adminHandler.DomainRules)
+			r.Post("/api/admin/domain-rules", adminHandler.StoreDomainRule)
+			r.Delete("/api/admin/domain-rules/{id}", adminHandler.DestroyDomainRule)
+		})
 	})
 	return r
-}
\ No newline at end of file
+}
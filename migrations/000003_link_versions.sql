@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE link_versions (
+    id SERIAL PRIMARY KEY,
+    short_link_id BIGINT NOT NULL,
+    version INT NOT NULL,
+    link TEXT NOT NULL,
+    short_link VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_link_versions_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT uq_link_versions_short_link_version
+        UNIQUE (short_link_id, version)
+);
+
+INSERT INTO link_versions (short_link_id, version, link, short_link, created_at)
+SELECT id, 1, link, short_link, updated_at FROM short_links;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_versions;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX idx_short_links_deleted_at ON short_links(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_short_links_short_link ON short_links(short_link);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_short_links_short_link;
+DROP INDEX IF EXISTS idx_short_links_deleted_at;
+ALTER TABLE short_links DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_short_links_created_at_id ON short_links(created_at, id);
+CREATE INDEX idx_short_links_clicks_id ON short_links(clicks, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_short_links_clicks_id;
+DROP INDEX IF EXISTS idx_short_links_created_at_id;
+-- +goose StatementEnd
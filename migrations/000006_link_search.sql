@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE short_links ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', short_link), 'A') ||
+        setweight(to_tsvector('simple', regexp_replace(link, '[^[:alnum:]]+', ' ', 'g')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_short_links_search_vector ON short_links USING GIN (search_vector);
+CREATE INDEX idx_short_links_link_trgm ON short_links USING GIN (link gin_trgm_ops);
+CREATE INDEX idx_short_links_name_trgm ON short_links USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_short_links_short_link_trgm ON short_links USING GIN (short_link gin_trgm_ops);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_short_links_short_link_trgm;
+DROP INDEX IF EXISTS idx_short_links_name_trgm;
+DROP INDEX IF EXISTS idx_short_links_link_trgm;
+DROP INDEX IF EXISTS idx_short_links_search_vector;
+ALTER TABLE short_links DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN description TEXT NULL;
+ALTER TABLE short_links ADD COLUMN notes TEXT NULL;
+
+ALTER TABLE link_versions ADD COLUMN name VARCHAR(255) NULL;
+ALTER TABLE link_versions ADD COLUMN description TEXT NULL;
+
+UPDATE link_versions lv SET name = sl.name FROM short_links sl WHERE sl.id = lv.short_link_id;
+
+ALTER TABLE short_links DROP COLUMN search_vector;
+ALTER TABLE short_links ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', short_link), 'A') ||
+        setweight(to_tsvector('simple', regexp_replace(link, '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'C') ||
+        setweight(to_tsvector('simple', coalesce(notes, '')), 'D')
+    ) STORED;
+
+CREATE INDEX idx_short_links_search_vector ON short_links USING GIN (search_vector);
+CREATE INDEX idx_short_links_description_trgm ON short_links USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_short_links_notes_trgm ON short_links USING GIN (notes gin_trgm_ops);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_short_links_notes_trgm;
+DROP INDEX IF EXISTS idx_short_links_description_trgm;
+
+ALTER TABLE short_links DROP COLUMN search_vector;
+ALTER TABLE short_links ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', short_link), 'A') ||
+        setweight(to_tsvector('simple', regexp_replace(link, '[^[:alnum:]]+', ' ', 'g')), 'B')
+    ) STORED;
+CREATE INDEX idx_short_links_search_vector ON short_links USING GIN (search_vector);
+
+ALTER TABLE link_versions DROP COLUMN IF EXISTS description;
+ALTER TABLE link_versions DROP COLUMN IF EXISTS name;
+ALTER TABLE short_links DROP COLUMN IF EXISTS notes;
+ALTER TABLE short_links DROP COLUMN IF EXISTS description;
+-- +goose StatementEnd
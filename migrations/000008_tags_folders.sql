@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_tags_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uq_tags_user_name ON tags(user_id, lower(name));
+
+CREATE TABLE link_tags (
+    short_link_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+
+    PRIMARY KEY (short_link_id, tag_id),
+
+    CONSTRAINT fk_link_tags_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_link_tags_tag
+        FOREIGN KEY (tag_id)
+        REFERENCES tags(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_link_tags_tag_id ON link_tags(tag_id);
+
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    parent_id BIGINT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_folders_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_folders_parent
+        FOREIGN KEY (parent_id)
+        REFERENCES folders(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_folders_user_id ON folders(user_id);
+CREATE INDEX idx_folders_parent_id ON folders(parent_id);
+
+ALTER TABLE short_links ADD COLUMN folder_id BIGINT NULL
+    CONSTRAINT fk_short_links_folder
+        REFERENCES folders(id)
+        ON DELETE SET NULL;
+
+CREATE INDEX idx_short_links_folder_id ON short_links(folder_id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE short_links DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
+DROP TABLE IF EXISTS link_tags;
+DROP TABLE IF EXISTS tags;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN disabled_reason VARCHAR(64) NULL;
+ALTER TABLE short_links ADD COLUMN disabled_at TIMESTAMP NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE short_links DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE short_links DROP COLUMN IF EXISTS disabled_reason;
+-- +goose StatementEnd
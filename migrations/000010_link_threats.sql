@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN threat_type VARCHAR(64) NULL;
+ALTER TABLE short_links ADD COLUMN threat_checked_at TIMESTAMP NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE short_links DROP COLUMN IF EXISTS threat_checked_at;
+ALTER TABLE short_links DROP COLUMN IF EXISTS threat_type;
+-- +goose StatementEnd
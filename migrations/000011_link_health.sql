@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE link_health (
+    short_link_id BIGINT PRIMARY KEY,
+    healthy BOOLEAN NOT NULL,
+    status_code INT NULL,
+    latency_ms INT NOT NULL DEFAULT 0,
+    redirect_chain TEXT[] NOT NULL DEFAULT '{}',
+    error TEXT NULL,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    notified_at TIMESTAMP NULL,
+    checked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_link_health_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_link_health_unhealthy ON link_health(short_link_id) WHERE NOT healthy;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_health;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN interstitial BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE short_links DROP COLUMN IF EXISTS interstitial;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE link_click_sources (
+    short_link_id BIGINT NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (short_link_id, source),
+
+    CONSTRAINT fk_link_click_sources_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_click_sources;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN utm_source VARCHAR(255) NULL;
+ALTER TABLE short_links ADD COLUMN utm_medium VARCHAR(255) NULL;
+ALTER TABLE short_links ADD COLUMN utm_campaign VARCHAR(255) NULL;
+ALTER TABLE short_links ADD COLUMN utm_term VARCHAR(255) NULL;
+ALTER TABLE short_links ADD COLUMN utm_content VARCHAR(255) NULL;
+ALTER TABLE short_links ADD COLUMN query_passthrough BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE short_links DROP COLUMN IF EXISTS query_passthrough;
+ALTER TABLE short_links DROP COLUMN IF EXISTS utm_content;
+ALTER TABLE short_links DROP COLUMN IF EXISTS utm_term;
+ALTER TABLE short_links DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE short_links DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE short_links DROP COLUMN IF EXISTS utm_source;
+-- +goose StatementEnd
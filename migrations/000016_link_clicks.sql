@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE link_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    short_link_id BIGINT NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    country CHAR(2) NOT NULL DEFAULT '',
+    region VARCHAR(6) NOT NULL DEFAULT '',
+    clicked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_link_clicks_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_link_clicks_short_link_clicked_at ON link_clicks(short_link_id, clicked_at);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS link_clicks;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE short_links ADD COLUMN variants JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE link_clicks ADD COLUMN variant VARCHAR(32) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS variant;
+ALTER TABLE short_links DROP COLUMN IF EXISTS variants;
+-- +goose StatementEnd
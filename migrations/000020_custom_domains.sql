@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE domains (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    host VARCHAR(253) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_domains_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_domains_user_id ON domains(user_id);
+
+-- Anyone may register a host, but only one registration can be verified:
+-- the first owner to publish the TXT record claims it. Unverified rows do
+-- not block other users.
+CREATE UNIQUE INDEX uq_domains_user_host ON domains(user_id, host);
+CREATE UNIQUE INDEX uq_domains_verified_host ON domains(host)
+    WHERE verified_at IS NOT NULL;
+
+-- Links without a domain live on the service's own host. Short codes are
+-- unique per domain, and a NULL domain counts as one more domain; Postgres
+-- 13 has no NULLS NOT DISTINCT, so that case gets its own partial index.
+ALTER TABLE short_links ADD COLUMN domain_id INT NULL
+    REFERENCES domains(id) ON DELETE RESTRICT;
+
+-- Short codes were not unique before, so older duplicates are renamed
+-- first. The oldest link keeps the code; the others get "~<id>" appended,
+-- which no generated code or alias contains.
+UPDATE short_links sl SET short_link = sl.short_link || '~' || sl.id
+WHERE EXISTS (
+    SELECT 1 FROM short_links older
+    WHERE older.short_link = sl.short_link AND older.id < sl.id
+);
+
+CREATE UNIQUE INDEX uq_short_links_short_link ON short_links(short_link)
+    WHERE domain_id IS NULL;
+CREATE UNIQUE INDEX uq_short_links_domain_short_link ON short_links(domain_id, short_link)
+    WHERE domain_id IS NOT NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_short_links_domain_short_link;
+DROP INDEX IF EXISTS uq_short_links_short_link;
+DROP INDEX IF EXISTS uq_domains_verified_host;
+DROP INDEX IF EXISTS uq_domains_user_host;
+ALTER TABLE short_links DROP COLUMN IF EXISTS domain_id;
+DROP TABLE IF EXISTS domains;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bot visits are kept out of clicks and link_click_sources and counted in
+-- bot_clicks instead.
+ALTER TABLE short_links ADD COLUMN bot_clicks BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE link_clicks ADD COLUMN bot BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS bot;
+ALTER TABLE short_links DROP COLUMN IF EXISTS bot_clicks;
+-- +goose StatementEnd
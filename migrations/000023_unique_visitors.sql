@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- HyperLogLog registers of each link's human visitors per UTC day.
+CREATE TABLE link_unique_visitors (
+    short_link_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    registers BYTEA NOT NULL,
+
+    PRIMARY KEY (short_link_id, day),
+    CONSTRAINT fk_link_unique_visitors_short_link
+        FOREIGN KEY (short_link_id)
+        REFERENCES short_links(id)
+        ON DELETE CASCADE
+);
+
+-- Salt of the visitor hashes. Only the current day's salt is kept, so hashes
+-- from earlier days cannot be recomputed from IP addresses.
+CREATE TABLE visitor_salts (
+    day DATE PRIMARY KEY,
+    salt BYTEA NOT NULL
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS visitor_salts;
+DROP TABLE IF EXISTS link_unique_visitors;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Versions also keep the settings that decide where visitors go. Older
+-- versions have none and reverting them leaves the settings as they are.
+ALTER TABLE link_versions ADD COLUMN settings JSONB NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE link_versions DROP COLUMN IF EXISTS settings;
+-- +goose StatementEnd
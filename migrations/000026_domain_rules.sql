@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Block and allow rules managed by admins through the API. They are applied
+-- together with the rules from DOMAIN_RULES_FILE.
+CREATE TABLE domain_rules (
+    id SERIAL PRIMARY KEY,
+    action VARCHAR(5) NOT NULL CHECK (action IN ('block', 'allow')),
+    pattern VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_domain_rules_action_pattern UNIQUE (action, pattern)
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS domain_rules;
+-- +goose StatementEnd
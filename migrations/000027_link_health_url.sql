@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The destination the stored result belongs to: the first failing one, or
+-- the link's own URL when all of them are healthy.
+ALTER TABLE link_health ADD COLUMN url TEXT NULL;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE link_health DROP COLUMN IF EXISTS url;
+-- +goose StatementEnd
@@ -0,0 +1,119 @@
+package hyperloglog
+
+import (
+	"math"
+	"testing"
+)
+
+// splitmix64 spreads consecutive integers over the whole hash space.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func TestPosition(t *testing.T) {
+	tests := []struct {
+		name  string
+		hash  uint64
+		index int
+		rank  byte
+	}{
+		{name: "zero hash has the highest rank", hash: 0, index: 0, rank: 64 - Precision + 1},
+		{name: "all ones", hash: math.MaxUint64, index: Registers - 1, rank: 1},
+		{name: "first bit after the index", hash: 1 << (63 - Precision), index: 0, rank: 1},
+		{name: "third bit after the index", hash: 1 << (61 - Precision), index: 0, rank: 3},
+		{name: "last bit", hash: 1, index: 0, rank: 64 - Precision},
+		{name: "index from the top bits", hash: 5 << (64 - Precision), index: 5, rank: 64 - Precision + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, rank := Position(tt.hash)
+			if index != tt.index || rank != tt.rank {
+				t.Errorf("Position(%#x) = (%d, %d), want (%d, %d)", tt.hash, index, rank, tt.index, tt.rank)
+			}
+		})
+	}
+}
+
+func TestPositionBounds(t *testing.T) {
+	for i := uint64(0); i < 100000; i++ {
+		index, rank := Position(splitmix64(i))
+		if index < 0 || index >= Registers {
+			t.Fatalf("index %d out of range", index)
+		}
+		if rank < 1 || rank > 64-Precision+1 {
+			t.Fatalf("rank %d out of range", rank)
+		}
+	}
+}
+
+func TestEstimateEmpty(t *testing.T) {
+	if got := New().Estimate(); got != 0 {
+		t.Errorf("Estimate of an empty sketch = %d, want 0", got)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	for _, n := range []uint64{1, 10, 100, 1000, 10000, 100000, 1000000} {
+		s := New()
+		for i := uint64(0); i < n; i++ {
+			s.Add(splitmix64(i))
+			// Duplicates must not change the estimate.
+			s.Add(splitmix64(i))
+		}
+
+		got := float64(s.Estimate())
+		// Small sets are counted almost exactly; large ones stay within
+		// three standard errors.
+		tolerance := math.Max(1, 3*0.0163*float64(n))
+		if math.Abs(got-float64(n)) > tolerance {
+			t.Errorf("Estimate for %d items = %.0f, want within %.0f", n, got, tolerance)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b, union := New(), New(), New()
+	for i := uint64(0); i < 30000; i++ {
+		a.Add(splitmix64(i))
+		union.Add(splitmix64(i))
+	}
+	for i := uint64(20000); i < 50000; i++ {
+		b.Add(splitmix64(i))
+		union.Add(splitmix64(i))
+	}
+
+	a.Merge(b)
+	if a.Estimate() != union.Estimate() {
+		t.Errorf("merged estimate %d, want %d as for the union", a.Estimate(), union.Estimate())
+	}
+	for i, rank := range a.Bytes() {
+		if rank != union.Bytes()[i] {
+			t.Fatalf("register %d = %d, want %d", i, rank, union.Bytes()[i])
+		}
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	s := New()
+	for i := uint64(0); i < 5000; i++ {
+		s.Add(splitmix64(i))
+	}
+
+	restored, err := FromBytes(s.Bytes())
+	if err != nil {
+		t.Fatalf("FromBytes: %v", err)
+	}
+	if restored.Estimate() != s.Estimate() {
+		t.Errorf("restored estimate %d, want %d", restored.Estimate(), s.Estimate())
+	}
+
+	for _, size := range []int{0, Registers - 1, Registers + 1} {
+		if _, err := FromBytes(make([]byte, size)); err == nil {
+			t.Errorf("FromBytes accepted %d bytes", size)
+		}
+	}
+}